@@ -0,0 +1,202 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// APIError is an error response of GitHub API.
+type APIError struct {
+	Method     string
+	URL        string
+	StatusCode int
+	Status     string
+	// Message is the message in the response body.
+	Message string
+}
+
+func (e *APIError) Error() string {
+	if e.Message == "" {
+		return fmt.Sprintf("%s %s: http status:%s", e.Method, e.URL, e.Status)
+	}
+	return fmt.Sprintf("%s %s: http status:%s(%s)", e.Method, e.URL, e.Status, e.Message)
+}
+
+// NotFoundError is returned when the resource does not exist, or is not visible with the token.
+type NotFoundError struct {
+	*APIError
+}
+
+// UnauthorizedError is returned when the token is missing or invalid.
+type UnauthorizedError struct {
+	*APIError
+}
+
+// ForbiddenError is returned when the token does not have enough permission, e.g. lack of `gist` scope.
+type ForbiddenError struct {
+	*APIError
+	// Scopes are scopes of the token.
+	Scopes string
+	// AcceptedScopes are scopes accepted by the API.
+	AcceptedScopes string
+}
+
+func (e *ForbiddenError) Error() string {
+	if e.AcceptedScopes == "" {
+		return e.APIError.Error()
+	}
+	return fmt.Sprintf("%s, token scopes: [%s], accepted scopes: [%s]", e.APIError.Error(), e.Scopes, e.AcceptedScopes)
+}
+
+// RateLimitError is returned when the rate limit of GitHub API is exceeded.
+type RateLimitError struct {
+	*APIError
+	RateLimit
+}
+
+func (e *RateLimitError) Error() string {
+	return fmt.Sprintf("%s, rate limit will be reset at %s", e.APIError.Error(), e.Reset.Format(time.RFC3339))
+}
+
+// ServerError is returned when GitHub fails to process the request.
+type ServerError struct {
+	*APIError
+}
+
+// RateLimit is a status of rate limit given by `X-RateLimit-*` headers.
+type RateLimit struct {
+	Limit     int
+	Remaining int
+	Reset     time.Time
+}
+
+// parseRateLimit reads `X-RateLimit-*` headers. If headers are not available, false will be returned.
+func parseRateLimit(header http.Header) (RateLimit, bool) {
+	limit, err := strconv.Atoi(header.Get("X-RateLimit-Limit"))
+	if err != nil {
+		return RateLimit{}, false
+	}
+	remaining, err := strconv.Atoi(header.Get("X-RateLimit-Remaining"))
+	if err != nil {
+		return RateLimit{}, false
+	}
+	reset, err := strconv.ParseInt(header.Get("X-RateLimit-Reset"), 10, 64)
+	if err != nil {
+		return RateLimit{}, false
+	}
+	return RateLimit{Limit: limit, Remaining: remaining, Reset: time.Unix(reset, 0)}, true
+}
+
+// checkResponse returns nil for 2xx response. Otherwise it returns typed error for the status.
+func checkResponse(response *http.Response, body []byte) error {
+	sc := response.StatusCode
+	if 200 <= sc && sc < 300 {
+		return nil
+	}
+	apiError := &APIError{
+		StatusCode: sc,
+		Status:     response.Status,
+	}
+	if response.Request != nil {
+		apiError.Method = response.Request.Method
+		apiError.URL = response.Request.URL.String()
+	}
+	var message struct {
+		Message string `json:"message"`
+	}
+	if json.Unmarshal(body, &message) == nil {
+		apiError.Message = message.Message
+	}
+
+	if sc == http.StatusForbidden || sc == http.StatusTooManyRequests {
+		rateLimit, ok := parseRateLimit(response.Header)
+		if ok && rateLimit.Remaining == 0 {
+			return &RateLimitError{APIError: apiError, RateLimit: rateLimit}
+		}
+		// secondary rate limit gives only Retry-After
+		if retryAfter, err := strconv.Atoi(response.Header.Get("Retry-After")); err == nil {
+			rateLimit.Reset = time.Now().Add(time.Duration(retryAfter) * time.Second)
+			return &RateLimitError{APIError: apiError, RateLimit: rateLimit}
+		}
+	}
+	switch {
+	case sc == http.StatusNotFound:
+		return &NotFoundError{apiError}
+	case sc == http.StatusUnauthorized:
+		return &UnauthorizedError{apiError}
+	case sc == http.StatusForbidden:
+		return &ForbiddenError{
+			APIError:       apiError,
+			Scopes:         response.Header.Get("X-OAuth-Scopes"),
+			AcceptedScopes: response.Header.Get("X-Accepted-OAuth-Scopes"),
+		}
+	case 500 <= sc:
+		return &ServerError{apiError}
+	}
+	return apiError
+}
+
+// rateLimitGuard stops bulk operations after the rate limit of GitHub API is hit.
+// If wait is true, operations are paused until the limit is reset instead.
+type rateLimitGuard struct {
+	wait    bool
+	mutex   sync.Mutex
+	limited *RateLimitError
+	// after and now are replaced in tests.
+	after func(time.Duration) <-chan time.Time
+	now   func() time.Time
+}
+
+func newRateLimitGuard(wait bool) *rateLimitGuard {
+	return &rateLimitGuard{wait: wait, after: time.After, now: time.Now}
+}
+
+// Observe records the error if it is caused by rate limit.
+func (g *rateLimitGuard) Observe(err error) {
+	var rateLimitError *RateLimitError
+	if !errors.As(err, &rateLimitError) {
+		return
+	}
+	g.mutex.Lock()
+	defer g.mutex.Unlock()
+	if g.limited == nil || g.limited.Reset.Before(rateLimitError.Reset) {
+		g.limited = rateLimitError
+	}
+}
+
+// Before is called before each operation. It waits until the limit is reset, or returns error to abort the operation.
+// Waiting is stopped when ctx is cancelled.
+func (g *rateLimitGuard) Before(ctx context.Context) error {
+	g.mutex.Lock()
+	limited := g.limited
+	g.mutex.Unlock()
+	if limited == nil {
+		return nil
+	}
+	if !g.wait {
+		return fmt.Errorf("aborted due to rate limit: %w", limited)
+	}
+	duration := limited.Reset.Sub(g.now())
+	if duration > 0 {
+		log.Printf("rate limit of GitHub API exceeded, waiting until %s\n", limited.Reset.Format(time.RFC3339))
+		// a second is added for gap between clocks
+		select {
+		case <-ctx.Done():
+			return fmt.Errorf("waiting for reset of rate limit is cancelled: %w", ctx.Err())
+		case <-g.after(duration + time.Second):
+		}
+	}
+	g.mutex.Lock()
+	if g.limited == limited {
+		g.limited = nil
+	}
+	g.mutex.Unlock()
+	return nil
+}
@@ -0,0 +1,133 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"github.com/stretchr/testify/assert"
+	"net/http"
+	"strconv"
+	"testing"
+	"time"
+)
+
+func TestGitHubImpl_GetGist_NotFoundError(t *testing.T) {
+	ctx, stop := startTestGitHubServer(func(writer http.ResponseWriter, request *http.Request) {
+		writer.WriteHeader(http.StatusNotFound)
+		_, _ = writer.Write([]byte(`{"message":"Not Found"}`))
+	})
+	defer stop()
+
+	_, err := ctx.NewGitHub().GetGist(context.Background(), "aa11", "default")
+	var notFound *NotFoundError
+	assert.True(t, errors.As(err, &notFound), err)
+	assert.Equal(t, "Not Found", notFound.Message)
+	assert.Equal(t, "GET", notFound.Method)
+}
+
+func TestGitHubImpl_DeleteGist_UnauthorizedError(t *testing.T) {
+	ctx, stop := startTestGitHubServer(func(writer http.ResponseWriter, request *http.Request) {
+		writer.WriteHeader(http.StatusUnauthorized)
+	})
+	defer stop()
+
+	err := ctx.NewGitHub().DeleteGist(context.Background(), "aa11", "default")
+	var unauthorized *UnauthorizedError
+	assert.True(t, errors.As(err, &unauthorized), err)
+}
+
+func TestGitHubImpl_CreateGist_ForbiddenError(t *testing.T) {
+	ctx, stop := startTestGitHubServer(func(writer http.ResponseWriter, request *http.Request) {
+		writer.Header().Set("X-OAuth-Scopes", "repo")
+		writer.Header().Set("X-Accepted-OAuth-Scopes", "gist")
+		writer.Header().Set("X-RateLimit-Limit", "5000")
+		writer.Header().Set("X-RateLimit-Remaining", "4999")
+		writer.Header().Set("X-RateLimit-Reset", "1577836800")
+		writer.WriteHeader(http.StatusForbidden)
+	})
+	defer stop()
+
+	_, err := ctx.NewGitHub().CreateGist(context.Background(), NewGist{}, "default")
+	var forbidden *ForbiddenError
+	assert.True(t, errors.As(err, &forbidden), err)
+	assert.Equal(t, "repo", forbidden.Scopes)
+	assert.Equal(t, "gist", forbidden.AcceptedScopes)
+	var rateLimited *RateLimitError
+	assert.False(t, errors.As(err, &rateLimited))
+}
+
+func TestGitHubImpl_ListGists_RateLimitError(t *testing.T) {
+	ctx, stop := startTestGitHubServer(func(writer http.ResponseWriter, request *http.Request) {
+		writer.Header().Set("X-RateLimit-Limit", "60")
+		writer.Header().Set("X-RateLimit-Remaining", "0")
+		writer.Header().Set("X-RateLimit-Reset", "1577836800")
+		writer.WriteHeader(http.StatusForbidden)
+	})
+	defer stop()
+
+	_, err := ctx.NewGitHub().ListGists(context.Background(), GistQuery{}, "default")
+	var rateLimited *RateLimitError
+	assert.True(t, errors.As(err, &rateLimited), err)
+	assert.Equal(t, RateLimit{Limit: 60, Remaining: 0, Reset: time.Unix(1577836800, 0)}, rateLimited.RateLimit)
+}
+
+func TestCheckResponse_RetryAfter(t *testing.T) {
+	header := http.Header{}
+	header.Set("Retry-After", strconv.Itoa(60))
+	err := checkResponse(&http.Response{StatusCode: http.StatusTooManyRequests, Status: "429", Header: header}, nil)
+	var rateLimited *RateLimitError
+	assert.True(t, errors.As(err, &rateLimited), err)
+	assert.True(t, rateLimited.Reset.After(time.Now()))
+}
+
+func TestCheckResponse_ServerError(t *testing.T) {
+	err := checkResponse(&http.Response{StatusCode: http.StatusBadGateway, Status: "502 Bad Gateway"}, []byte("<html>"))
+	var serverError *ServerError
+	assert.True(t, errors.As(err, &serverError), err)
+	assert.Equal(t, "", serverError.Message)
+}
+
+func TestCheckResponse_Success(t *testing.T) {
+	assert.Nil(t, checkResponse(&http.Response{StatusCode: http.StatusNoContent}, nil))
+}
+
+func TestRateLimitGuard_Abort(t *testing.T) {
+	guard := newRateLimitGuard(false)
+	assert.Nil(t, guard.Before(context.Background()))
+	guard.Observe(errors.New("other error"))
+	assert.Nil(t, guard.Before(context.Background()))
+	guard.Observe(&RateLimitError{APIError: &APIError{}, RateLimit: RateLimit{Reset: time.Now().Add(time.Hour)}})
+	err := guard.Before(context.Background())
+	var rateLimited *RateLimitError
+	assert.True(t, errors.As(err, &rateLimited), err)
+}
+
+func TestRateLimitGuard_Wait(t *testing.T) {
+	now := time.Unix(1577836800, 0)
+	var slept time.Duration
+	guard := newRateLimitGuard(true)
+	guard.now = func() time.Time { return now }
+	guard.after = func(duration time.Duration) <-chan time.Time {
+		slept = duration
+		return time.After(0)
+	}
+	guard.Observe(&RateLimitError{APIError: &APIError{}, RateLimit: RateLimit{Reset: now.Add(time.Minute)}})
+
+	assert.Nil(t, guard.Before(context.Background()))
+	assert.Equal(t, time.Minute+time.Second, slept)
+	slept = 0
+	assert.Nil(t, guard.Before(context.Background()))
+	assert.Equal(t, time.Duration(0), slept)
+}
+
+func TestRateLimitGuard_Wait_Cancelled(t *testing.T) {
+	guard := newRateLimitGuard(true)
+	guard.Observe(&RateLimitError{APIError: &APIError{}, RateLimit: RateLimit{Reset: time.Now().Add(time.Hour)}})
+	ctx, cancel := context.WithCancel(context.Background())
+	go func() {
+		time.Sleep(10 * time.Millisecond)
+		cancel()
+	}()
+
+	err := guard.Before(ctx)
+	assert.True(t, errors.Is(err, context.Canceled), err)
+}
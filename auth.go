@@ -0,0 +1,68 @@
+package main
+
+import (
+	"fmt"
+	"gopkg.in/src-d/go-git.v4"
+	"gopkg.in/src-d/go-git.v4/plumbing/transport"
+	githttp "gopkg.in/src-d/go-git.v4/plumbing/transport/http"
+	gitssh "gopkg.in/src-d/go-git.v4/plumbing/transport/ssh"
+	"strings"
+)
+
+// GitAuth returns AuthMethod of git for remoteURL with credentials of the profile.
+// https url uses GitHubAccessToken, and ssh url uses SSHKeyFile of the profile or ssh agent if it is not set.
+// nil is returned for local url, https url when the profile has no token, and http url,
+// so that the token is never sent in cleartext.
+// The token is sent only to GistHost of the profile, so that it does not leak to other hosts.
+func (context *ProfileContext) GitAuth(profileName ProfileName, remoteURL string) (transport.AuthMethod, error) {
+	switch {
+	case strings.HasPrefix(remoteURL, "https://"):
+		gistHost, err := context.GistHost(profileName)
+		if err != nil {
+			return nil, fmt.Errorf("ProfileContext_GitAuth_GistHost: %w", err)
+		}
+		if !strings.HasPrefix(remoteURL, fmt.Sprintf("https://%s/", gistHost)) {
+			return nil, nil
+		}
+		token, err := context.Token(profileName)
+		if err != nil {
+			return nil, fmt.Errorf("ProfileContext_GitAuth_Token: %w", err)
+		}
+		if token == "" {
+			return nil, nil
+		}
+		// GitHub accepts any non-empty user name with an access token as password.
+		return &githttp.BasicAuth{Username: "gist", Password: string(token)}, nil
+	case strings.HasPrefix(remoteURL, "ssh://") || strings.HasPrefix(remoteURL, "git@"):
+		keyFile, err := context.SSHKeyFile(profileName)
+		if err != nil {
+			return nil, fmt.Errorf("ProfileContext_GitAuth_SSHKeyFile: %w", err)
+		}
+		if keyFile != "" {
+			auth, err := gitssh.NewPublicKeysFromFile("git", string(keyFile), "")
+			if err != nil {
+				return nil, fmt.Errorf("ProfileContext_GitAuth_PublicKeys(%s): %w", keyFile, err)
+			}
+			return auth, nil
+		}
+		auth, err := gitssh.NewSSHAgentAuth("git")
+		if err != nil {
+			return nil, fmt.Errorf("ProfileContext_GitAuth_SSHAgent: %w", err)
+		}
+		return auth, nil
+	}
+	return nil, nil
+}
+
+// RemoteAuth returns AuthMethod for origin of the repository with credentials of the profile.
+func (context *ProfileContext) RemoteAuth(profileName ProfileName, repository *git.Repository) (transport.AuthMethod, error) {
+	remote, err := repository.Remote(git.DefaultRemoteName)
+	if err != nil {
+		return nil, fmt.Errorf("ProfileContext_RemoteAuth_Remote: %w", err)
+	}
+	urls := remote.Config().URLs
+	if len(urls) == 0 {
+		return nil, fmt.Errorf("ProfileContext_RemoteAuth: no url for %s", git.DefaultRemoteName)
+	}
+	return context.GitAuth(profileName, urls[0])
+}
@@ -0,0 +1,118 @@
+package main
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/pem"
+	"github.com/stretchr/testify/assert"
+	githttp "gopkg.in/src-d/go-git.v4/plumbing/transport/http"
+	gitssh "gopkg.in/src-d/go-git.v4/plumbing/transport/ssh"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestProfileContext_GitAuth_HTTPS(t *testing.T) {
+	context := ProfileContext{
+		CurrentProfiles: []Profile{{Name: "default", Token: "a0b1c2d3e4f5"}},
+	}
+	auth, err := context.GitAuth("default", "https://gist.github.com/aa11.git")
+	assert.Nil(t, err)
+	assert.Equal(t, &githttp.BasicAuth{Username: "gist", Password: "a0b1c2d3e4f5"}, auth)
+}
+
+func TestProfileContext_GitAuth_HTTPS_NoToken(t *testing.T) {
+	context := ProfileContext{
+		CurrentProfiles: []Profile{{Name: "default"}},
+	}
+	auth, err := context.GitAuth("default", "https://gist.github.com/aa11.git")
+	assert.Nil(t, err)
+	assert.Nil(t, auth)
+}
+
+func TestProfileContext_GitAuth_HTTP(t *testing.T) {
+	context := ProfileContext{
+		CurrentProfiles: []Profile{{Name: "default", Token: "aa00bb11cc22"}},
+	}
+	auth, err := context.GitAuth("default", "http://github.example.com/gist/0a1b2c3d4e5f.git")
+	assert.Nil(t, err)
+	assert.Nil(t, auth)
+}
+
+func TestProfileContext_GitAuth_HTTPS_OtherHost(t *testing.T) {
+	context := ProfileContext{
+		CurrentProfiles: []Profile{{Name: "default", Token: "a0b1c2d3e4f5"}},
+	}
+	for _, url := range []string{"https://gist.example.com/aa11.git", "https://gist.github.com.example.com/aa11.git"} {
+		auth, err := context.GitAuth("default", url)
+		assert.Nil(t, err)
+		assert.Nil(t, auth, url)
+	}
+}
+
+func TestProfileContext_GitAuth_HTTPS_EnterpriseHost(t *testing.T) {
+	context := ProfileContext{
+		CurrentProfiles: []Profile{{Name: "default", Token: "a0b1c2d3e4f5", GistHost: "github.example.com/gist"}},
+	}
+	auth, err := context.GitAuth("default", "https://github.example.com/gist/aa11.git")
+	assert.Nil(t, err)
+	assert.Equal(t, &githttp.BasicAuth{Username: "gist", Password: "a0b1c2d3e4f5"}, auth)
+	auth, err = context.GitAuth("default", "https://gist.github.com/aa11.git")
+	assert.Nil(t, err)
+	assert.Nil(t, auth)
+}
+
+func TestProfileContext_GitAuth_LocalPath(t *testing.T) {
+	context := ProfileContext{
+		CurrentProfiles: []Profile{{Name: "default", Token: "a0b1c2d3e4f5"}},
+	}
+	auth, err := context.GitAuth("default", "/tmp/origin")
+	assert.Nil(t, err)
+	assert.Nil(t, auth)
+}
+
+func TestProfileContext_GitAuth_UnknownProfile(t *testing.T) {
+	context := ProfileContext{}
+	_, err := context.GitAuth("default", "https://gist.github.com/aa11.git")
+	assert.NotNil(t, err)
+}
+
+func TestProfileContext_GitAuth_SSHKeyFile(t *testing.T) {
+	dir, err := ioutil.TempDir("", "gist-auth-")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() { _ = os.RemoveAll(dir) }()
+	keyFile := filepath.Join(dir, "id_rsa")
+	err = writeTestPrivateKey(keyFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	context := ProfileContext{
+		CurrentProfiles: []Profile{{Name: "default", SSHKeyFile: SSHKeyFile(keyFile)}},
+	}
+	auth, err := context.GitAuth("default", "git@gist.github.com:aa11.git")
+	assert.Nil(t, err)
+	publicKeys, ok := auth.(*gitssh.PublicKeys)
+	assert.True(t, ok)
+	assert.Equal(t, "git", publicKeys.User)
+}
+
+func TestProfileContext_GitAuth_SSHKeyFile_NotExisting(t *testing.T) {
+	context := ProfileContext{
+		CurrentProfiles: []Profile{{Name: "default", SSHKeyFile: "build/test/not-existing-key"}},
+	}
+	_, err := context.GitAuth("default", "git@gist.github.com:aa11.git")
+	assert.NotNil(t, err)
+}
+
+func writeTestPrivateKey(keyFile string) error {
+	key, err := rsa.GenerateKey(rand.Reader, 1024)
+	if err != nil {
+		return err
+	}
+	bytes := pem.EncodeToMemory(&pem.Block{Type: "RSA PRIVATE KEY", Bytes: x509.MarshalPKCS1PrivateKey(key)})
+	return ioutil.WriteFile(keyFile, bytes, 0600)
+}
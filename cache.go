@@ -0,0 +1,161 @@
+package main
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"path/filepath"
+)
+
+// CacheDir is a directory where responses of GitHub API are cached.
+type CacheDir string
+
+// CacheDir returns CacheDir of given profile. If user home is unknown, empty CacheDir will be returned and cache is disabled.
+func (context *ProfileContext) CacheDir(profileName ProfileName) (CacheDir, error) {
+	for _, profile := range context.CurrentProfiles {
+		if profile.Name == profileName {
+			if context.EnvValues.UserHome == "" {
+				return "", nil
+			}
+			return CacheDir(fmt.Sprintf("%s/.gist-cache/%s", context.EnvValues.UserHome, profileName)), nil
+		}
+	}
+	return "", fmt.Errorf("no profile found(name = %s)", profileName)
+}
+
+// ResponseCache stores responses of GitHub API with their ETag and Last-Modified,
+// so that unchanged resources are served from the cache with 304 response.
+type ResponseCache struct {
+	CacheDir
+}
+
+// cacheEntry is a cached response.
+type cacheEntry struct {
+	URL          string `json:"url"`
+	ETag         string `json:"etag,omitempty"`
+	LastModified string `json:"last_modified,omitempty"`
+	// Link is kept for pagination.
+	Link string `json:"link,omitempty"`
+	Body []byte `json:"body"`
+}
+
+func (rc *ResponseCache) path(requestURL string) string {
+	hash := sha256.Sum256([]byte(requestURL))
+	return filepath.Join(string(rc.CacheDir), hex.EncodeToString(hash[:]))
+}
+
+// Load returns cached response of the url. If not cached, nil will be returned.
+func (rc *ResponseCache) Load(requestURL string) *cacheEntry {
+	if rc.CacheDir == "" {
+		return nil
+	}
+	bs, err := ioutil.ReadFile(rc.path(requestURL))
+	if err != nil {
+		return nil
+	}
+	var entry cacheEntry
+	err = json.Unmarshal(bs, &entry)
+	if err != nil || entry.URL != requestURL {
+		return nil
+	}
+	return &entry
+}
+
+// Store caches the response if it has ETag or Last-Modified.
+func (rc *ResponseCache) Store(requestURL string, response *http.Response, body []byte) error {
+	if rc.CacheDir == "" || response.StatusCode != http.StatusOK {
+		return nil
+	}
+	entry := cacheEntry{
+		URL:          requestURL,
+		ETag:         response.Header.Get("ETag"),
+		LastModified: response.Header.Get("Last-Modified"),
+		Link:         response.Header.Get("Link"),
+		Body:         body,
+	}
+	if entry.ETag == "" && entry.LastModified == "" {
+		return nil
+	}
+	bs, err := json.Marshal(entry)
+	if err != nil {
+		return fmt.Errorf("ResponseCache_Store_MarshalJson: %w", err)
+	}
+	err = os.MkdirAll(string(rc.CacheDir), 0700)
+	if err != nil {
+		return fmt.Errorf("ResponseCache_Store_MkdirAll: %w", err)
+	}
+	err = ioutil.WriteFile(rc.path(requestURL), bs, 0600)
+	if err != nil {
+		return fmt.Errorf("ResponseCache_Store_WriteFile: %w", err)
+	}
+	return nil
+}
+
+// Clear removes all cached responses.
+func (rc *ResponseCache) Clear() error {
+	if rc.CacheDir == "" {
+		return nil
+	}
+	return os.RemoveAll(string(rc.CacheDir))
+}
+
+// conditions adds headers of conditional request.
+func (entry *cacheEntry) conditions(request *http.Request) {
+	if entry.ETag != "" {
+		request.Header.Set("If-None-Match", entry.ETag)
+	}
+	if entry.LastModified != "" {
+		request.Header.Set("If-Modified-Since", entry.LastModified)
+	}
+}
+
+// restore turns 304 response into 200 response with cached headers.
+func (entry *cacheEntry) restore(response *http.Response) {
+	response.StatusCode = http.StatusOK
+	response.Status = "200 OK(cached)"
+	if entry.Link != "" && response.Header.Get("Link") == "" {
+		response.Header.Set("Link", entry.Link)
+	}
+}
+
+// CacheClearCommand removes cached responses of GitHub API.
+type CacheClearCommand struct {
+	ProfileName
+	// All removes caches of all profiles.
+	All    bool
+	Writer io.Writer
+}
+
+// Run command of CacheClearCommand
+func (cc *CacheClearCommand) Run(ctx context.Context, pctx ProfileContext) error {
+	if cc.Writer == nil {
+		return errors.New("CacheClearCommand_Run: writer is not given")
+	}
+	profileNames := []ProfileName{cc.ProfileName}
+	if cc.All {
+		profileNames = make([]ProfileName, len(pctx.CurrentProfiles))
+		for i, profile := range pctx.CurrentProfiles {
+			profileNames[i] = profile.Name
+		}
+	}
+	for _, profileName := range profileNames {
+		cacheDir, err := pctx.CacheDir(profileName)
+		if err != nil {
+			return fmt.Errorf("CacheClearCommand_Run_CacheDir: %w", err)
+		}
+		cache := ResponseCache{CacheDir: cacheDir}
+		err = cache.Clear()
+		if err != nil {
+			return fmt.Errorf("CacheClearCommand_Run_Clear(%s): %w", profileName, err)
+		}
+		_, _ = fmt.Fprintf(cc.Writer, "cleared cache of %s\n", profileName)
+	}
+	return nil
+}
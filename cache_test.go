@@ -0,0 +1,136 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"github.com/stretchr/testify/assert"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestGitHubImpl_GetGist_ServedFromCache(t *testing.T) {
+	home, err := ioutil.TempDir("", "cache-test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() { _ = os.RemoveAll(home) }()
+	requests := 0
+	ctx, stop := startTestGitHubServer(func(writer http.ResponseWriter, request *http.Request) {
+		requests++
+		if request.Header.Get("If-None-Match") == `"v1"` {
+			writer.WriteHeader(http.StatusNotModified)
+			return
+		}
+		assert.Equal(t, 1, requests)
+		writer.Header().Set("ETag", `"v1"`)
+		_, _ = writer.Write([]byte(`{"id":"aa11","description":"cached"}`))
+	})
+	defer stop()
+	ctx.EnvValues.UserHome = UserHome(home)
+
+	gitHub := ctx.NewGitHub()
+	for i := 0; i < 2; i++ {
+		gist, err := gitHub.GetGist(context.Background(), "aa11", "default")
+		assert.Nil(t, err)
+		assert.Equal(t, "cached", gist.Description)
+	}
+	assert.Equal(t, 2, requests)
+}
+
+func TestGitHubImpl_ListGists_CachedLink(t *testing.T) {
+	home, err := ioutil.TempDir("", "cache-test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() { _ = os.RemoveAll(home) }()
+	var serverURL string
+	ctx, stop := startTestGitHubServer(func(writer http.ResponseWriter, request *http.Request) {
+		if request.Header.Get("If-Modified-Since") != "" {
+			writer.WriteHeader(http.StatusNotModified)
+			return
+		}
+		writer.Header().Set("Last-Modified", "Wed, 01 Jan 2020 00:00:00 GMT")
+		if request.URL.Query().Get("page") == "2" {
+			_, _ = writer.Write([]byte(`[{"id":"bb22"}]`))
+			return
+		}
+		writer.Header().Set("Link", `<`+serverURL+`/gists?page=2>; rel="next"`)
+		_, _ = writer.Write([]byte(`[{"id":"aa11"}]`))
+	})
+	defer stop()
+	serverURL = githubAPIBaseURL
+	ctx.EnvValues.UserHome = UserHome(home)
+
+	gitHub := ctx.NewGitHub()
+	for i := 0; i < 2; i++ {
+		gists, err := gitHub.ListGists(context.Background(), GistQuery{}, "default")
+		assert.Nil(t, err)
+		assert.Equal(t, 2, len(gists))
+	}
+}
+
+func TestResponseCache_StoreWithoutValidator(t *testing.T) {
+	dir, err := ioutil.TempDir("", "cache-test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() { _ = os.RemoveAll(dir) }()
+	cache := ResponseCache{CacheDir: CacheDir(dir)}
+	err = cache.Store("https://example.com/gists", &http.Response{StatusCode: http.StatusOK, Header: http.Header{}}, []byte("[]"))
+	assert.Nil(t, err)
+	assert.Nil(t, cache.Load("https://example.com/gists"))
+}
+
+func TestProfileContext_CacheDir(t *testing.T) {
+	ctx := ProfileContext{
+		EnvValues:       EnvValues{UserHome: "/home/test"},
+		CurrentProfiles: []Profile{{Name: "default"}},
+	}
+	cacheDir, err := ctx.CacheDir("default")
+	assert.Nil(t, err)
+	assert.Equal(t, CacheDir("/home/test/.gist-cache/default"), cacheDir)
+	_, err = ctx.CacheDir("unknown")
+	assert.NotNil(t, err)
+
+	ctx.EnvValues.UserHome = ""
+	cacheDir, err = ctx.CacheDir("default")
+	assert.Nil(t, err)
+	assert.Equal(t, CacheDir(""), cacheDir)
+}
+
+func TestCacheClearCommand_Run(t *testing.T) {
+	home, err := ioutil.TempDir("", "cache-test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() { _ = os.RemoveAll(home) }()
+	for _, name := range []string{"default", "work"} {
+		err = os.MkdirAll(filepath.Join(home, ".gist-cache", name), 0700)
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	ctx := ProfileContext{
+		EnvValues:       EnvValues{UserHome: UserHome(home)},
+		CurrentProfiles: []Profile{{Name: "default"}, {Name: "work"}},
+	}
+
+	buffer := new(bytes.Buffer)
+	command := CacheClearCommand{ProfileName: "default", Writer: buffer}
+	err = command.Run(context.Background(), ctx)
+	assert.Nil(t, err)
+	assert.Equal(t, "cleared cache of default\n", buffer.String())
+	_, err = os.Stat(filepath.Join(home, ".gist-cache", "default"))
+	assert.True(t, os.IsNotExist(err))
+	_, err = os.Stat(filepath.Join(home, ".gist-cache", "work"))
+	assert.Nil(t, err)
+
+	command = CacheClearCommand{All: true, Writer: new(bytes.Buffer)}
+	err = command.Run(context.Background(), ctx)
+	assert.Nil(t, err)
+	_, err = os.Stat(filepath.Join(home, ".gist-cache", "work"))
+	assert.True(t, os.IsNotExist(err))
+}
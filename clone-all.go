@@ -0,0 +1,67 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+)
+
+// CloneAllCommand clones all gists of the profile's user, which are not cloned yet.
+type CloneAllCommand struct {
+	ProfileName
+	PreferSSH
+	Jobs int
+	// WaitRateLimit waits until rate limit of GitHub API is reset. Otherwise remaining gists are not cloned.
+	WaitRateLimit bool
+	Writer        io.Writer
+}
+
+// Run command of CloneAllCommand
+func (ca *CloneAllCommand) Run(ctx context.Context, pctx ProfileContext) error {
+	destinationDir, err := pctx.Dir(ca.ProfileName)
+	if err != nil {
+		return fmt.Errorf("CloneAllCommand_Run_ProfileContext_Dir: %w", err)
+	}
+	metadataFile, err := destinationDir.Resolve(".gist")
+	if err != nil {
+		return fmt.Errorf("CloneAllCommand_Run_Resolve: %w", err)
+	}
+	metadataIndex, err := LoadMetadataIndex(metadataFile)
+	if err != nil {
+		return fmt.Errorf("CloneAllCommand_Run_LoadMetadataIndex: %w", err)
+	}
+	items := metadataIndex.Items()
+	recorded := make(map[string]bool, len(items))
+	for _, md := range items {
+		recorded[md.ID] = true
+	}
+
+	gitHub := pctx.NewGitHub()
+	gists, err := gitHub.ListGists(ctx, GistQuery{}, ca.ProfileName)
+	if err != nil {
+		return fmt.Errorf("CloneAllCommand_Run_ListGists: %w", err)
+	}
+
+	skipped := make([]GistID, 0)
+	targets := make([]GistID, 0)
+	for _, gist := range gists {
+		gistID := GistID(gist.ID)
+		if recorded[gist.ID] {
+			skipped = append(skipped, gistID)
+			continue
+		}
+		targets = append(targets, gistID)
+	}
+	command := ParallelCloneCommand{
+		ProfileName:   ca.ProfileName,
+		PreferSSH:     ca.PreferSSH,
+		GistIDs:       targets,
+		Jobs:          ca.Jobs,
+		WaitRateLimit: ca.WaitRateLimit,
+		Writer:        ca.Writer,
+	}
+	summary := command.Clone(ctx, pctx)
+	summary.Skipped = skipped
+	_, _ = fmt.Fprintln(ca.Writer, summary.String())
+	return summary.Err()
+}
@@ -0,0 +1,34 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"github.com/stretchr/testify/assert"
+	"net/http"
+	"os"
+	"testing"
+)
+
+func TestCloneAllCommand_Run_SkipsRecordedGists(t *testing.T) {
+	err := os.MkdirAll("build/test/github", 0755)
+	if err != nil {
+		assert.Fail(t, "unexpected error@mkdir", err)
+		return
+	}
+	_ = os.Remove("build/test/github/.gist")
+	saveTestMetadata(t, "build/test/github/.gist", RepositoryMetadata{ID: "aa11", Owner: "test-user"}, RepositoryMetadata{ID: "bb22", Owner: "test-user"})
+	requests := 0
+	ctx, stop := startTestGitHubServer(func(writer http.ResponseWriter, request *http.Request) {
+		requests++
+		assert.Equal(t, "/gists", request.URL.Path)
+		_, _ = writer.Write([]byte(`[{"id":"aa11"},{"id":"bb22"}]`))
+	})
+	defer stop()
+
+	buffer := new(bytes.Buffer)
+	command := CloneAllCommand{ProfileName: "default", Jobs: 2, Writer: buffer}
+	err = command.Run(context.Background(), ctx)
+	assert.Nil(t, err)
+	assert.Equal(t, 1, requests)
+	assert.Equal(t, "cloned: 0, skipped: 2, failed: 0\n", buffer.String())
+}
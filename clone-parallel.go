@@ -0,0 +1,187 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"strings"
+	"sync"
+)
+
+// ParallelCloneCommand clones multiple gists concurrently with bounded number of workers.
+type ParallelCloneCommand struct {
+	ProfileName
+	PreferSSH
+	GistIDs []GistID
+	// Jobs is the max number of clones running at the same time.
+	Jobs int
+	// WaitRateLimit waits until rate limit of GitHub API is reset. Otherwise remaining gists are not cloned.
+	WaitRateLimit bool
+	Writer        io.Writer
+}
+
+// CloneFailure is a gist failed to be cloned, with its cause.
+type CloneFailure struct {
+	GistID
+	Err error
+}
+
+// CloneSummary is a result of cloning multiple gists.
+type CloneSummary struct {
+	Cloned  []GistID
+	Skipped []GistID
+	Failed  []CloneFailure
+}
+
+// String shows counts of the summary.
+func (s *CloneSummary) String() string {
+	return fmt.Sprintf("cloned: %d, skipped: %d, failed: %d", len(s.Cloned), len(s.Skipped), len(s.Failed))
+}
+
+// Err returns error aggregating all failures, or nil if there are no failed gists.
+func (s *CloneSummary) Err() error {
+	if len(s.Failed) == 0 {
+		return nil
+	}
+	causes := make([]string, len(s.Failed))
+	for i, failure := range s.Failed {
+		causes[i] = fmt.Sprintf("%s: %v", failure.GistID, failure.Err)
+	}
+	return fmt.Errorf("failed to clone %d gists(%s)", len(s.Failed), strings.Join(causes, "; "))
+}
+
+// Run command of ParallelCloneCommand
+func (pc *ParallelCloneCommand) Run(ctx context.Context, pctx ProfileContext) error {
+	summary := pc.Clone(ctx, pctx)
+	_, _ = fmt.Fprintln(pc.Writer, summary.String())
+	return summary.Err()
+}
+
+// Clone clones all gists, and returns the summary of them.
+// Metadata of cloned gists are written into metadata file one by one.
+// After ctx is cancelled, remaining gists are not cloned and reported as failures.
+func (pc *ParallelCloneCommand) Clone(ctx context.Context, pctx ProfileContext) *CloneSummary {
+	jobs := pc.Jobs
+	if jobs < 1 {
+		jobs = 1
+	}
+	gistIDs := make(chan GistID)
+	results := make(chan cloneResult)
+
+	var recording sync.Mutex
+	var workers sync.WaitGroup
+	guard := newRateLimitGuard(pc.WaitRateLimit)
+	for i := 0; i < jobs; i++ {
+		workers.Add(1)
+		go func() {
+			defer workers.Done()
+			for gistID := range gistIDs {
+				if ctx.Err() != nil {
+					results <- cloneResult{GistID: gistID, err: ctx.Err()}
+					continue
+				}
+				err := guard.Before(ctx)
+				if err != nil {
+					results <- cloneResult{GistID: gistID, err: err}
+					continue
+				}
+				result := pc.cloneOne(ctx, pctx, gistID, &recording)
+				guard.Observe(result.err)
+				results <- result
+			}
+		}()
+	}
+	go func() {
+		for i, gistID := range pc.GistIDs {
+			select {
+			case gistIDs <- gistID:
+				continue
+			case <-ctx.Done():
+			}
+			for _, cancelled := range pc.GistIDs[i:] {
+				results <- cloneResult{GistID: cancelled, err: ctx.Err()}
+			}
+			break
+		}
+		close(gistIDs)
+		workers.Wait()
+		close(results)
+	}()
+
+	summary := CloneSummary{}
+	total := len(pc.GistIDs)
+	for result := range results {
+		progress := len(summary.Cloned) + len(summary.Failed) + 1
+		if result.err != nil {
+			_, _ = fmt.Fprintf(pc.Writer, "[%d/%d] failed %s: %v\n", progress, total, result.GistID, result.err)
+			summary.Failed = append(summary.Failed, CloneFailure{GistID: result.GistID, Err: result.err})
+			continue
+		}
+		_, _ = fmt.Fprintf(pc.Writer, "[%d/%d] cloned %s into %s\n", progress, total, result.GistID, result.directory)
+		summary.Cloned = append(summary.Cloned, result.GistID)
+	}
+	return &summary
+}
+
+type cloneResult struct {
+	GistID
+	directory string
+	err       error
+}
+
+func (pc *ParallelCloneCommand) cloneOne(ctx context.Context, pctx ProfileContext, gistID GistID, recording *sync.Mutex) cloneResult {
+	command := CloneCommand{
+		GistID:      gistID,
+		ProfileName: pc.ProfileName,
+		PreferSSH:   pc.PreferSSH,
+	}
+	cloned, err := command.CloneRepository(ctx, pctx)
+	if err != nil {
+		return cloneResult{GistID: gistID, err: err}
+	}
+	recording.Lock()
+	defer recording.Unlock()
+	err = cloned.Record()
+	if err != nil {
+		return cloneResult{GistID: gistID, err: err}
+	}
+	return cloneResult{GistID: gistID, directory: cloned.Directory}
+}
+
+// ReadGistIDs reads gist ids from reader, one id per line.
+// Blank lines and lines starting with `#` are ignored, and duplicated ids are removed.
+func ReadGistIDs(reader io.Reader) ([]GistID, error) {
+	gistIDs := make([]GistID, 0)
+	scanner := bufio.NewScanner(reader)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		gistID, err := ParseGistReference(line)
+		if err != nil {
+			return nil, fmt.Errorf("ReadGistIDs_ParseGistReference(%s): %w", line, err)
+		}
+		gistIDs = append(gistIDs, *gistID)
+	}
+	err := scanner.Err()
+	if err != nil {
+		return nil, fmt.Errorf("ReadGistIDs_Scan: %w", err)
+	}
+	return UniqueGistIDs(gistIDs), nil
+}
+
+// UniqueGistIDs removes duplicated ids keeping their order.
+func UniqueGistIDs(gistIDs []GistID) []GistID {
+	found := make(map[GistID]bool, len(gistIDs))
+	unique := make([]GistID, 0, len(gistIDs))
+	for _, gistID := range gistIDs {
+		if found[gistID] {
+			continue
+		}
+		found[gistID] = true
+		unique = append(unique, gistID)
+	}
+	return unique
+}
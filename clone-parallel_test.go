@@ -0,0 +1,97 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"github.com/stretchr/testify/assert"
+	"sort"
+	"strings"
+	"testing"
+)
+
+func TestCloneSummary_String(t *testing.T) {
+	summary := CloneSummary{
+		Cloned:  []GistID{"aa11", "bb22"},
+		Skipped: []GistID{"cc33"},
+	}
+	assert.Equal(t, "cloned: 2, skipped: 1, failed: 0", summary.String())
+	assert.Nil(t, summary.Err())
+}
+
+func TestCloneSummary_Err(t *testing.T) {
+	summary := CloneSummary{
+		Failed: []CloneFailure{
+			{GistID: "aa11", Err: errors.New("not found")},
+			{GistID: "bb22", Err: errors.New("not empty")},
+		},
+	}
+	err := summary.Err()
+	assert.NotNil(t, err)
+	assert.Equal(t, "failed to clone 2 gists(aa11: not found; bb22: not empty)", err.Error())
+}
+
+func TestParallelCloneCommand_Clone_AggregatesFailures(t *testing.T) {
+	// no profile exists, then every clone fails before accessing network.
+	ctx := ProfileContext{}
+	buffer := new(bytes.Buffer)
+	command := ParallelCloneCommand{
+		ProfileName: "default",
+		GistIDs:     []GistID{"aa11", "bb22", "cc33"},
+		Jobs:        2,
+		Writer:      buffer,
+	}
+	summary := command.Clone(context.Background(), ctx)
+	assert.Equal(t, 0, len(summary.Cloned))
+	failed := make([]string, len(summary.Failed))
+	for i, failure := range summary.Failed {
+		failed[i] = string(failure.GistID)
+	}
+	sort.Strings(failed)
+	assert.Equal(t, []string{"aa11", "bb22", "cc33"}, failed)
+	assert.NotNil(t, summary.Err())
+	assert.Equal(t, 3, strings.Count(buffer.String(), "failed"))
+	assert.True(t, strings.HasPrefix(buffer.String(), "[1/3] failed "), buffer.String())
+}
+
+func TestParallelCloneCommand_Clone_Cancelled(t *testing.T) {
+	ctx := ProfileContext{}
+	buffer := new(bytes.Buffer)
+	command := ParallelCloneCommand{
+		ProfileName: "default",
+		GistIDs:     []GistID{"aa11", "bb22"},
+		Jobs:        1,
+		Writer:      buffer,
+	}
+	cancelled, cancel := context.WithCancel(context.Background())
+	cancel()
+	summary := command.Clone(cancelled, ctx)
+	assert.Equal(t, 0, len(summary.Cloned))
+	assert.Equal(t, 2, len(summary.Failed))
+	for _, failure := range summary.Failed {
+		assert.True(t, errors.Is(failure.Err, context.Canceled), failure.Err)
+	}
+}
+
+func TestReadGistIDs(t *testing.T) {
+	reader := strings.NewReader(`
+# gists to restore
+aa11
+  bb22  
+aa11
+
+cc33
+`)
+	gistIDs, err := ReadGistIDs(reader)
+	assert.Nil(t, err)
+	assert.Equal(t, []GistID{"aa11", "bb22", "cc33"}, gistIDs)
+}
+
+func TestReadGistIDs_InvalidID(t *testing.T) {
+	_, err := ReadGistIDs(strings.NewReader("aa11\nnot-a-gist\n"))
+	assert.NotNil(t, err)
+}
+
+func TestUniqueGistIDs(t *testing.T) {
+	assert.Equal(t, []GistID{"bb22", "aa11"}, UniqueGistIDs([]GistID{"bb22", "aa11", "bb22"}))
+}
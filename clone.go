@@ -1,12 +1,15 @@
 package main
 
 import (
+	"context"
 	"errors"
 	"fmt"
 	"gopkg.in/src-d/go-git.v4"
+	"gopkg.in/src-d/go-git.v4/plumbing/transport"
 	"io"
 	"log"
 	"os"
+	"path/filepath"
 	"regexp"
 	"strings"
 )
@@ -52,13 +55,22 @@ func (s *PreferSSH) String() string {
 	panic(fmt.Sprintf("unknown ssh value: %d", s))
 }
 
-// BaseURL is gist base url
-func (s *PreferSSH) BaseURL() string {
+// BaseURL is gist base url of the host. If host is empty, gist.github.com is used.
+func (s *PreferSSH) BaseURL(host GistHost) string {
+	if host == "" {
+		host = defaultGistHost
+	}
 	switch *s {
 	case https:
-		return "https://gist.github.com/"
+		return fmt.Sprintf("https://%s/", host)
 	case ssh:
-		return "git@gist.github.com:"
+		// host may contain path(e.g. github.example.com/gist), which follows colon in scp-like syntax.
+		h := string(host)
+		index := strings.Index(h, "/")
+		if index < 0 {
+			return fmt.Sprintf("git@%s:", h)
+		}
+		return fmt.Sprintf("git@%s:%s/", h[:index], h[index+1:])
 	}
 	panic(fmt.Sprintf("unknown ssh value: %d", s))
 }
@@ -72,6 +84,10 @@ type CloneCommand struct {
 	ProfileName
 	PreferSSH
 	RepositoryName
+	// GistHost is host of gist repository. If empty, gist.github.com is used.
+	GistHost
+	// Auth is credentials for git clone. If nil, clones without authentication.
+	Auth transport.AuthMethod
 }
 
 // DirName is directory name for clone command.
@@ -88,67 +104,219 @@ func (cc *CloneCommand) NameIsID() bool {
 }
 
 // Run command of CloneCommand
-func (cc *CloneCommand) Run(ctx ProfileContext) error {
+func (cc *CloneCommand) Run(ctx context.Context, pctx ProfileContext) error {
+	cloned, err := cc.CloneRepository(ctx, pctx)
+	if err != nil {
+		return err
+	}
+	fmt.Println(cloned.Directory)
+	return cloned.Record()
+}
+
+// ClonedGist is a cloned gist repository whose metadata is not recorded yet.
+type ClonedGist struct {
+	Directory    string
+	MetadataFile string
+	Metadata     RepositoryMetadata
+	// existed is whether Directory existed before clone.
+	existed bool
+}
+
+// CloneRepository retrieves metadata of gist and clones it, but does not write metadata into metadata file.
+// If any step fails or is cancelled, the directory created by clone is removed.
+func (cc *CloneCommand) CloneRepository(ctx context.Context, pctx ProfileContext) (*ClonedGist, error) {
 	// determine destination dir
-	destinationDir, err := ctx.Dir(cc.ProfileName)
+	destinationDir, err := pctx.Dir(cc.ProfileName)
 	if err != nil {
-		return fmt.Errorf("CloneCommand_Run_ProfileContext_Dir: %w", err)
+		return nil, fmt.Errorf("CloneCommand_Run_ProfileContext_Dir: %w", err)
 	}
-	// resolve destination dir
-	targetDirectory, err := destinationDir.Resolve(cc.DirName())
+	// resolve repository file under destination dir
+	metadataFile, err := destinationDir.Resolve(".gist")
 	if err != nil {
-		return fmt.Errorf("CloneCommand_Run_Resolve: %w", err)
+		return nil, fmt.Errorf("CloneCommand_GitHub_MetadataFile: %w", err)
 	}
-	fmt.Println(targetDirectory)
-	// test directory is empty or not existing
-	err = prepareDirectory(targetDirectory)
+	// get info on gist before clone, so that no directory is left without metadata
+	gitHub := pctx.NewGitHub()
+	gist, err := gitHub.GetGist(ctx, cc.GistID, cc.ProfileName)
 	if err != nil {
-		return err
+		return nil, fmt.Errorf("CloneCommand_GitHub_Metadata: %w", err)
+	}
+	metadata, err := NewMetadataFromGist(cc.RepositoryName, *gist)
+	if err != nil {
+		return nil, fmt.Errorf("CloneCommand_GitHub_CreateMetadata: %w", err)
+	}
+	layout, err := pctx.Layout(cc.ProfileName)
+	if err != nil {
+		return nil, fmt.Errorf("CloneCommand_Run_Layout: %w", err)
+	}
+	// name the directory with template of the profile, or test directory is empty or not existing
+	existed := false
+	nameTemplate, err := pctx.NameTemplate(cc.ProfileName)
+	if err != nil {
+		return nil, fmt.Errorf("CloneCommand_Run_NameTemplate: %w", err)
+	}
+	err = checkLayoutParentOf(*metadata, layout.Parent(*metadata), metadataFile)
+	if err != nil {
+		return nil, err
+	}
+	reserved := false
+	if cc.RepositoryName == "" && nameTemplate != "" {
+		reserved, err = cc.reserveTemplateName(nameTemplate, *gist, destinationDir, layout.Parent(*metadata), metadataFile)
+		if err != nil {
+			return nil, err
+		}
+	}
+	metadata.Name = string(cc.RepositoryName)
+	relativePath := layout.Path(*metadata, cc.DirName())
+	if relativePath != metadata.DirName() {
+		metadata.Path = relativePath
+	}
+	// resolve destination dir
+	targetDirectory, err := destinationDir.Resolve(relativePath)
+	if err != nil {
+		return nil, fmt.Errorf("CloneCommand_Run_Resolve: %w", err)
+	}
+	if !reserved {
+		existed, err = prepareDirectory(targetDirectory)
+		if err != nil {
+			return nil, err
+		}
 	}
 	// execute git clone
-	err = cc.Clone(targetDirectory)
+	if cc.GistHost == "" {
+		cc.GistHost, err = pctx.GistHost(cc.ProfileName)
+		if err != nil {
+			cleanupDirectory(targetDirectory, existed)
+			return nil, fmt.Errorf("CloneCommand_Run_GistHost: %w", err)
+		}
+	}
+	cc.Auth, err = pctx.GitAuth(cc.ProfileName, cc.URL())
 	if err != nil {
-		return fmt.Errorf("CloneCommand_Run_Clone: %w", err)
+		cleanupDirectory(targetDirectory, existed)
+		return nil, fmt.Errorf("CloneCommand_Run_GitAuth: %w", err)
 	}
-	// get info on gist
-	gitHub := ctx.NewGitHub()
-	gist, err := gitHub.GetGist(cc.GistID, cc.ProfileName)
+	attempts := 0
+	err = runGitOperation(ctx, pctx, cc.ProfileName, fmt.Sprintf("clone %s", cc.GistID), func(ctx context.Context) error {
+		if attempts > 0 {
+			// files of the failed attempt are removed, so that it is cloned into empty directory
+			cleanupDirectory(targetDirectory, true)
+		}
+		attempts++
+		return cc.Clone(ctx, targetDirectory)
+	})
 	if err != nil {
-		log.Printf("clone %s Success, but failed to retreive metadata\n", cc.URL())
-		return fmt.Errorf("CloneCommand_GitHub_Metadata: %w", err)
+		cleanupDirectory(targetDirectory, existed)
+		return nil, fmt.Errorf("CloneCommand_Run_Clone: %w", err)
 	}
-	// write info into repository file under destination dir
-	metadataFile, err := destinationDir.Resolve(".gist")
+	return &ClonedGist{
+		Directory:    targetDirectory,
+		MetadataFile: metadataFile,
+		Metadata:     *metadata,
+		existed:      existed,
+	}, nil
+}
+
+// checkLayoutParentOf tests the parent directory of the gist is not in the directory of another recorded gist.
+func checkLayoutParentOf(metadata RepositoryMetadata, parent string, metadataFile string) error {
+	if parent == "" {
+		return nil
+	}
+	metadataIndex, err := LoadMetadataIndex(metadataFile)
 	if err != nil {
-		log.Printf("clone %s Success, but failed to retreive metadata\n", cc.URL())
-		return fmt.Errorf("CloneCommand_GitHub_MetadataFile: %w", err)
+		return fmt.Errorf("CloneCommand_Run_LoadMetadataIndex: %w", err)
 	}
-	metadata, err := NewMetadataFromGist(cc.RepositoryName, *gist)
+	err = checkLayoutParent(metadataIndex.Items(), metadata.ID, parent)
+	if err != nil {
+		return fmt.Errorf("CloneCommand_Run_CheckLayoutParent: %w", err)
+	}
+	return nil
+}
+
+// reserveTemplateName renders name of the gist with the template, and creates its directory under parent.
+// The name is set as RepositoryName unless it is the same as id of the gist.
+// It returns whether the directory is created, which is false when the gist is cloned again into its recorded directory.
+func (cc *CloneCommand) reserveTemplateName(nameTemplate NameTemplate, gist Gist, destinationDir DestinationDir, parent string, metadataFile string) (bool, error) {
+	name, err := nameTemplate.Render(gist)
+	if err != nil {
+		return false, fmt.Errorf("CloneCommand_Run_RenderName: %w", err)
+	}
+	metadataIndex, err := LoadMetadataIndex(metadataFile)
 	if err != nil {
-		log.Printf("clone %s Success, but failed to parse metadata(%v)\n", cc.URL(), err)
-		return fmt.Errorf("CloneCommand_GitHub_CreateMetadata: %w", err)
+		return false, fmt.Errorf("CloneCommand_Run_LoadMetadataIndex: %w", err)
 	}
-	err = metadata.AppendTo(metadataFile)
+	name, created, err := reserveDirectory(destinationDir, parent, name, gist.ID, metadataIndex.Items())
 	if err != nil {
+		return false, fmt.Errorf("CloneCommand_Run_ReserveDirectory: %w", err)
+	}
+	if name != string(cc.GistID) {
+		cc.RepositoryName = RepositoryName(name)
+	}
+	return created, nil
+}
+
+// Record writes metadata of the cloned gist into metadata file, replacing the entry of the same gist.
+// If it fails, the cloned directory is removed so that it is not left untracked.
+func (cg *ClonedGist) Record() error {
+	err := cg.record()
+	if err != nil {
+		cleanupDirectory(cg.Directory, cg.existed)
 		return fmt.Errorf("CloneCommand_GitHub_WriteMetadata: %w", err)
 	}
 	return nil
 }
 
-func prepareDirectory(targetDirectory string) error {
+func (cg *ClonedGist) record() error {
+	metadataIndex, err := LoadMetadataIndex(cg.MetadataFile)
+	if err != nil {
+		return err
+	}
+	metadataIndex.Upsert(cg.Metadata)
+	return metadataIndex.Save()
+}
+
+// prepareDirectory tests the directory is empty or not existing, and returns whether it exists.
+func prepareDirectory(targetDirectory string) (bool, error) {
 	result, err := testDestinationDir(targetDirectory)
 	if err != nil {
-		return fmt.Errorf("CloneCommand_Run_TestDir: %w", err)
+		return false, fmt.Errorf("CloneCommand_Run_TestDir: %w", err)
 	}
 	if result != resultEmptyDir && result != resultNotExistingDir {
-		return fmt.Errorf("CloneCommand_Run_TestDir: %s is not empty directory", targetDirectory)
+		return false, fmt.Errorf("CloneCommand_Run_TestDir: %s is not empty directory", targetDirectory)
 	} else if result == resultNotExistingDir {
 		err := createParentDirectory(targetDirectory)
 		if err != nil {
-			return fmt.Errorf("CloneCommand_Run_CreateParentDir(%s): %w", targetDirectory, err)
+			return false, fmt.Errorf("CloneCommand_Run_CreateParentDir(%s): %w", targetDirectory, err)
+		}
+	}
+	return result == resultEmptyDir, nil
+}
+
+// cleanupDirectory removes the directory created by clone. If the directory existed before, only its contents are removed.
+func cleanupDirectory(directory string, existed bool) {
+	if !existed {
+		err := os.RemoveAll(directory)
+		if err != nil {
+			log.Printf("failed to remove %s: %v\n", directory, err)
+		}
+		return
+	}
+	file, err := os.Open(directory)
+	if err != nil {
+		log.Printf("failed to clean up %s: %v\n", directory, err)
+		return
+	}
+	names, err := file.Readdirnames(-1)
+	_ = file.Close()
+	if err != nil {
+		log.Printf("failed to clean up %s: %v\n", directory, err)
+		return
+	}
+	for _, name := range names {
+		err := os.RemoveAll(filepath.Join(directory, name))
+		if err != nil {
+			log.Printf("failed to clean up %s: %v\n", directory, err)
 		}
 	}
-	return nil
 }
 
 type testDirResult int
@@ -192,14 +360,15 @@ func createParentDirectory(directory string) error {
 
 // URL is gist git url
 func (cc *CloneCommand) URL() string {
-	return fmt.Sprintf("%s%s.git", cc.BaseURL(), cc.GistID)
+	return fmt.Sprintf("%s%s.git", cc.BaseURL(cc.GistHost), cc.GistID)
 }
 
 // Clone clones gist repository.
-func (cc *CloneCommand) Clone(directory string) error {
+func (cc *CloneCommand) Clone(ctx context.Context, directory string) error {
 	url := cc.URL()
-	_, err := git.PlainClone(directory, false, &git.CloneOptions{
-		URL: url,
+	_, err := git.PlainCloneContext(ctx, directory, false, &git.CloneOptions{
+		URL:  url,
+		Auth: cc.Auth,
 	})
 	if err != nil {
 		return fmt.Errorf("GitClone(%s): %w", url, err)
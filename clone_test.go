@@ -1,12 +1,15 @@
 package main
 
 import (
+	"context"
 	"fmt"
 	"github.com/stretchr/testify/assert"
 	"gopkg.in/src-d/go-git.v4"
 	"gopkg.in/src-d/go-git.v4/plumbing"
 	"io/ioutil"
+	"net/http"
 	"os"
+	"path/filepath"
 	"testing"
 )
 
@@ -93,7 +96,7 @@ func TestCloneCommand_Clone(t *testing.T) {
 		PreferSSH:      https,
 		RepositoryName: "gist-example",
 	}
-	err := command.Clone("build/clone/test/gist-example")
+	err := command.Clone(context.Background(), "build/clone/test/gist-example")
 	assert.Nil(t, err)
 	dir, err := os.Open("build/clone/test/gist-example")
 	assert.Nil(t, err)
@@ -131,3 +134,114 @@ func TestNewGistID_InvalidID2(t *testing.T) {
 	assert.NotNil(t, err)
 	assert.Nil(t, gistID)
 }
+
+func TestCloneCommand_URL_HTTPS_GistHost(t *testing.T) {
+	command := CloneCommand{
+		GistID:      "11aa22bb33cc",
+		ProfileName: "default",
+		PreferSSH:   https,
+		GistHost:    "github.example.com/gist",
+	}
+	assert.Equal(t, "https://github.example.com/gist/11aa22bb33cc.git", command.URL())
+}
+
+func TestCloneCommand_URL_SSH_GistHost(t *testing.T) {
+	command := CloneCommand{
+		GistID:      "11aa22bb33cc",
+		ProfileName: "default",
+		PreferSSH:   ssh,
+		GistHost:    "github.example.com/gist",
+	}
+	assert.Equal(t, "git@github.example.com:gist/11aa22bb33cc.git", command.URL())
+}
+
+func TestCloneCommand_URL_SSH_GistHostWithoutPath(t *testing.T) {
+	command := CloneCommand{
+		GistID:      "11aa22bb33cc",
+		ProfileName: "default",
+		PreferSSH:   ssh,
+		GistHost:    "gist.example.com",
+	}
+	assert.Equal(t, "git@gist.example.com:11aa22bb33cc.git", command.URL())
+}
+
+func TestCleanupDirectory_Created(t *testing.T) {
+	parent, err := ioutil.TempDir("", "gist-cleanup-")
+	assert.Nil(t, err)
+	defer func() { _ = os.RemoveAll(parent) }()
+	directory := parent + "/aa11"
+	assert.Nil(t, os.MkdirAll(directory+"/.git", 0755))
+
+	cleanupDirectory(directory, false)
+	_, err = os.Stat(directory)
+	assert.True(t, os.IsNotExist(err))
+}
+
+func TestCleanupDirectory_Existed(t *testing.T) {
+	directory, err := ioutil.TempDir("", "gist-cleanup-")
+	assert.Nil(t, err)
+	defer func() { _ = os.RemoveAll(directory) }()
+	assert.Nil(t, os.MkdirAll(directory+"/.git", 0755))
+	assert.Nil(t, ioutil.WriteFile(directory+"/main.go", []byte("package main"), 0644))
+
+	cleanupDirectory(directory, true)
+	result, err := testDestinationDir(directory)
+	assert.Nil(t, err)
+	assert.Equal(t, resultEmptyDir, result)
+}
+
+func TestCloneCommand_CloneRepository_MetadataFailureCreatesNoDirectory(t *testing.T) {
+	ctx, stop := startTestGitHubServer(func(writer http.ResponseWriter, request *http.Request) {
+		writer.WriteHeader(http.StatusNotFound)
+	})
+	defer stop()
+	parent, err := ioutil.TempDir("", "gist-clone-")
+	assert.Nil(t, err)
+	defer func() { _ = os.RemoveAll(parent) }()
+	ctx.CurrentProfiles[0].Dir = DestinationDir(parent)
+
+	command := CloneCommand{GistID: "aa11", ProfileName: "default"}
+	_, err = command.CloneRepository(context.Background(), ctx)
+	assert.NotNil(t, err)
+	_, err = os.Stat(parent + "/aa11")
+	assert.True(t, os.IsNotExist(err))
+}
+
+func TestClonedGist_Record_RemovesDirectoryOnFailure(t *testing.T) {
+	parent, err := ioutil.TempDir("", "gist-clone-")
+	assert.Nil(t, err)
+	defer func() { _ = os.RemoveAll(parent) }()
+	assert.Nil(t, os.MkdirAll(parent+"/aa11/.git", 0755))
+	// metadata file cannot be written, because it is a directory.
+	assert.Nil(t, os.MkdirAll(parent+"/.gist", 0755))
+
+	cloned := ClonedGist{
+		Directory:    parent + "/aa11",
+		MetadataFile: parent + "/.gist",
+		Metadata:     RepositoryMetadata{ID: "aa11"},
+	}
+	err = cloned.Record()
+	assert.NotNil(t, err)
+	_, err = os.Stat(parent + "/aa11")
+	assert.True(t, os.IsNotExist(err))
+}
+
+func TestCloneCommand_CloneRepository_ParentIsAnotherGist(t *testing.T) {
+	ctx, stop := startTestGitHubServer(func(writer http.ResponseWriter, request *http.Request) {
+		_, _ = writer.Write([]byte(`{"id":"bb22","created_at":"2020-01-02T03:04:05Z","owner":{"login":"alice"}}`))
+	})
+	defer stop()
+	parent, err := ioutil.TempDir("", "gist-layout-")
+	assert.Nil(t, err)
+	defer func() { _ = os.RemoveAll(parent) }()
+	ctx.CurrentProfiles[0].Dir = DestinationDir(parent)
+	ctx.CurrentProfiles[0].Layout = LayoutOwner
+	saveTestMetadata(t, filepath.Join(parent, ".gist"), RepositoryMetadata{ID: "aa11", Name: "alice"})
+	command := CloneCommand{GistID: "bb22", ProfileName: "default", GistHost: "127.0.0.1:1"}
+
+	_, err = command.CloneRepository(context.Background(), ctx)
+	assert.NotNil(t, err)
+	assert.Contains(t, err.Error(), "directory alice is used by gist aa11")
+	_, err = os.Stat(filepath.Join(parent, "alice", "bb22"))
+	assert.True(t, os.IsNotExist(err))
+}
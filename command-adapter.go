@@ -1,9 +1,14 @@
 package main
 
 import (
+	"context"
 	"errors"
 	"fmt"
 	"github.com/urfave/cli/v2"
+	"os"
+	"os/signal"
+	"strings"
+	"time"
 )
 
 // GetApplication assembles application.
@@ -15,6 +20,22 @@ func GetApplication(args []string) Application {
 		Commands: []*cli.Command{
 			profileCommand(&envValues, &fileFlag),
 			cloneCommand(&envValues, &fileFlag),
+			listCommand(&envValues, &fileFlag),
+			remoteListCommand(&envValues, &fileFlag),
+			pullCommand(&envValues, &fileFlag),
+			createCommand(&envValues, &fileFlag),
+			pushCommand(&envValues, &fileFlag),
+			deleteCommand(&envValues, &fileFlag),
+			editCommand(&envValues, &fileFlag),
+			starCommand(&envValues, &fileFlag, false),
+			starCommand(&envValues, &fileFlag, true),
+			forkCommand(&envValues, &fileFlag),
+			commentsCommand(&envValues, &fileFlag),
+			historyCommand(&envValues, &fileFlag),
+			diffCommand(&envValues, &fileFlag),
+			cacheCommand(&envValues, &fileFlag),
+			repairCommand(&envValues, &fileFlag),
+			relayoutCommand(&envValues, &fileFlag),
 		},
 	}
 	return &CliApp{
@@ -42,37 +63,93 @@ type CliApp struct {
 	App  cli.App
 }
 
-// Start starts application
+// Start starts application. Interrupt signal cancels the running command.
 func (app *CliApp) Start() error {
-	return app.App.Run(app.Args)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, os.Interrupt)
+	defer signal.Stop(signals)
+	go func() {
+		select {
+		case <-signals:
+			cancel()
+		case <-ctx.Done():
+		}
+	}()
+	return app.App.RunContext(ctx, app.Args)
 }
 
 func profileCommand(envValues *EnvValues, fileFlag *string) *cli.Command {
 	var name string
 	var token string
 	var dir string
+	var sshKeyFile string
+	var apiBaseURL string
+	var gistHost string
+	var timeout string
+	var retries int
+	var nameTemplate string
+	var layout string
 	return &cli.Command{
 		Name:    "profile",
 		Aliases: []string{"p"},
 		Usage:   "add or update profile configuration",
 		Action: func(context *cli.Context) error {
-			return profileCommandAction(envValues, *fileFlag, name, token, dir)
+			return profileCommandAction(context, envValues, *fileFlag, name, token, dir, sshKeyFile, apiBaseURL, gistHost, timeout, retries, nameTemplate, layout)
 		},
 		Flags: []cli.Flag{
 			profileFlag(&name),
 			tokenFlag(&token),
 			destinationDirectoryFlag(&dir),
+			sshKeyFileFlag(&sshKeyFile),
+			&cli.StringFlag{
+				Name:        "api-base-url",
+				Usage:       "Base url of GitHub API for this profile(e.g. https://github.example.com/api/v3)",
+				Required:    false,
+				Value:       "",
+				Destination: &apiBaseURL,
+			},
+			&cli.StringFlag{
+				Name:        "gist-host",
+				Usage:       "Host of gist repositories for this profile(e.g. github.example.com/gist)",
+				Required:    false,
+				Value:       "",
+				Destination: &gistHost,
+			},
+			&cli.StringFlag{
+				Name:        "timeout",
+				Usage:       "Timeout of each request to GitHub API and each attempt of git clone, pull and push for this profile(e.g. 30s)",
+				Required:    false,
+				Value:       "",
+				Destination: &timeout,
+			},
+			&cli.IntFlag{
+				Name:        "retries",
+				Usage:       "Max number of retries of failed requests to GitHub API and git clone, pull and push for this profile(0: default 3)",
+				Required:    false,
+				Value:       0,
+				Destination: &retries,
+			},
+			&cli.StringFlag{
+				Name:        "name-template",
+				Usage:       "Template of directory name of gists cloned without name for this profile(e.g. {{.Owner}}-{{.Slug}})",
+				Required:    false,
+				Value:       "",
+				Destination: &nameTemplate,
+			},
+			layoutFlag(&layout),
 		},
 	}
 }
 
-func profileCommandAction(envValues *EnvValues, fileFlag string, name string, token string, dir string) error {
+func profileCommandAction(context *cli.Context, envValues *EnvValues, fileFlag string, name string, token string, dir string, sshKeyFile string, apiBaseURL string, gistHost string, timeout string, retries int, nameTemplate string, layout string) error {
 	ctx, err := envValues.NewContext(ProfileFile(fileFlag))
 	if err != nil {
 		return fmt.Errorf("ProfileCommand_NewContext: %w", err)
 	}
-	command := NewProfileCommand(&name, &token, &dir)
-	err = command.Run(ctx)
+	command := NewProfileCommand(&name, &token, &dir, &sshKeyFile, &apiBaseURL, &gistHost, &timeout, &retries, &nameTemplate, &layout, context.IsSet)
+	err = command.Run(context.Context, ctx)
 	if err != nil {
 		return fmt.Errorf("ProfileCommandAction: %w", err)
 	}
@@ -90,6 +167,16 @@ func profileFlag(name *string) *cli.StringFlag {
 	}
 }
 
+func layoutFlag(layout *string) *cli.StringFlag {
+	return &cli.StringFlag{
+		Name:        "layout",
+		Usage:       "Layout of cloned gists(flat, owner/id, year/month/id or language/id)",
+		Required:    false,
+		Value:       "",
+		Destination: layout,
+	}
+}
+
 func tokenFlag(token *string) *cli.StringFlag {
 	return &cli.StringFlag{
 		Name:        "token",
@@ -112,43 +199,174 @@ func destinationDirectoryFlag(dir *string) *cli.StringFlag {
 	}
 }
 
+func sshKeyFileFlag(sshKeyFile *string) *cli.StringFlag {
+	return &cli.StringFlag{
+		Name:        "ssh-key",
+		Aliases:     []string{"k"},
+		Usage:       "Private key file for ssh. If not set, ssh agent is used",
+		Required:    false,
+		Value:       "",
+		Destination: sshKeyFile,
+	}
+}
+
 func cloneCommand(envValues *EnvValues, fileFlag *string) *cli.Command {
 	var profileName string
 	var preferSSH bool
 	var repoName string
+	var all bool
+	var input string
+	var jobs int
+	var waitRateLimit bool
 	return &cli.Command{
-		Name:    "clone",
-		Aliases: []string{"c"},
-		Usage:   "clones specified gist",
+		Name:      "clone",
+		Aliases:   []string{"c"},
+		Usage:     "clones specified gists",
+		ArgsUsage: "[gist-id...]",
 		Flags: []cli.Flag{
 			profileFlag(&profileName),
 			preferSSHFlag(&preferSSH),
 			repositoryName(&repoName),
+			&cli.BoolFlag{
+				Name:        "all",
+				Aliases:     []string{"a"},
+				Usage:       "clones all gists of the profile's user, which are not cloned yet",
+				Required:    false,
+				Value:       false,
+				Destination: &all,
+			},
+			&cli.StringFlag{
+				Name:        "input",
+				Aliases:     []string{"i"},
+				Usage:       "file containing gist ids, one id per line. if - is given, ids are read from stdin",
+				Required:    false,
+				Value:       "",
+				Destination: &input,
+			},
+			jobsFlag(&jobs),
+			waitRateLimitFlag(&waitRateLimit),
 		},
 		Action: func(context *cli.Context) error {
-			gistID := context.Args().First()
-			if gistID == "" {
-				return errors.New("gist id is required")
+			if all {
+				if context.Args().Present() || repoName != "" || input != "" {
+					return errors.New("neither gist id, name nor input can be given with -all")
+				}
+				command := CloneAllCommand{
+					ProfileName:   ProfileName(profileName),
+					PreferSSH:     PreferSSHFromBool(preferSSH),
+					Jobs:          jobs,
+					WaitRateLimit: waitRateLimit,
+					Writer:        os.Stdout,
+				}
+				ctx, err := envValues.NewContext(ProfileFile(*fileFlag))
+				if err != nil {
+					return fmt.Errorf("CloneAllCommand_NewContext: %w", err)
+				}
+				return command.Run(context.Context, ctx)
 			}
-			id, err := NewGistID(gistID)
+			gistIDs, err := cloneTargets(context.Args().Slice(), input)
 			if err != nil {
-				return fmt.Errorf("CloneCommand_NewGistID: %w", err)
+				return fmt.Errorf("CloneCommand_GistIDs: %w", err)
 			}
-			command := CloneCommand{
-				GistID:         *id,
-				ProfileName:    ProfileName(profileName),
-				PreferSSH:      PreferSSHFromBool(preferSSH),
-				RepositoryName: RepositoryName(repoName),
+			if len(gistIDs) == 0 {
+				return errors.New("gist id is required")
 			}
 			ctx, err := envValues.NewContext(ProfileFile(*fileFlag))
 			if err != nil {
 				return fmt.Errorf("CloneCommand_NewContext: %w", err)
 			}
-			return command.Run(ctx)
+			if len(gistIDs) == 1 {
+				command := CloneCommand{
+					GistID:         gistIDs[0],
+					ProfileName:    ProfileName(profileName),
+					PreferSSH:      PreferSSHFromBool(preferSSH),
+					RepositoryName: RepositoryName(repoName),
+				}
+				return command.Run(context.Context, ctx)
+			}
+			if repoName != "" {
+				return errors.New("name cannot be given for multiple gists")
+			}
+			command := ParallelCloneCommand{
+				ProfileName:   ProfileName(profileName),
+				PreferSSH:     PreferSSHFromBool(preferSSH),
+				GistIDs:       gistIDs,
+				Jobs:          jobs,
+				WaitRateLimit: waitRateLimit,
+				Writer:        os.Stdout,
+			}
+			return command.Run(context.Context, ctx)
 		},
 	}
 }
 
+// cloneTargets collects gist ids from arguments and input file(or stdin if input is -).
+func cloneTargets(args []string, input string) ([]GistID, error) {
+	err := checkMisplacedFlags(args)
+	if err != nil {
+		return nil, err
+	}
+	gistIDs := make([]GistID, 0, len(args))
+	for _, arg := range args {
+		if arg == "-" {
+			input = arg
+			continue
+		}
+		id, err := ParseGistReference(arg)
+		if err != nil {
+			return nil, fmt.Errorf("ParseGistReference: %w", err)
+		}
+		gistIDs = append(gistIDs, *id)
+	}
+	if input == "" {
+		return UniqueGistIDs(gistIDs), nil
+	}
+	reader := os.Stdin
+	if input != "-" {
+		file, err := os.Open(input)
+		if err != nil {
+			return nil, fmt.Errorf("OpenInput: %w", err)
+		}
+		defer func() { _ = file.Close() }()
+		reader = file
+	}
+	ids, err := ReadGistIDs(reader)
+	if err != nil {
+		return nil, fmt.Errorf("ReadInput: %w", err)
+	}
+	return UniqueGistIDs(append(gistIDs, ids...)), nil
+}
+
+// checkMisplacedFlags fails on flags given after arguments, which are not parsed by cli but taken as arguments.
+// `-` alone is an argument meaning stdin.
+func checkMisplacedFlags(args []string) error {
+	for _, arg := range args {
+		if len(arg) > 1 && strings.HasPrefix(arg, "-") {
+			return fmt.Errorf("flag %s is given after arguments, flags should be given before arguments", arg)
+		}
+	}
+	return nil
+}
+
+// checkNoArguments fails on arguments given to a command which takes no arguments.
+func checkNoArguments(context *cli.Context) error {
+	if context.Args().Len() > 0 {
+		return fmt.Errorf("unexpected arguments: %s. usage: gist %s [options]", strings.Join(context.Args().Slice(), " "), context.Command.FullName())
+	}
+	return nil
+}
+
+func jobsFlag(jobs *int) cli.Flag {
+	return &cli.IntFlag{
+		Name:        "jobs",
+		Aliases:     []string{"j"},
+		Usage:       "max number of gists processed at the same time",
+		Required:    false,
+		Value:       4,
+		Destination: jobs,
+	}
+}
+
 func preferSSHFlag(preferSSH *bool) cli.Flag {
 	return &cli.BoolFlag{
 		Name:        "ssh",
@@ -170,3 +388,906 @@ func repositoryName(repoName *string) cli.Flag {
 		Destination: repoName,
 	}
 }
+
+func listCommand(envValues *EnvValues, fileFlag *string) *cli.Command {
+	var profileName string
+	var output string
+	var limit int
+	var page int
+	var sortOrder string
+	var starred bool
+	var language string
+	var visibility string
+	var updatedSince string
+	return &cli.Command{
+		Name:    "list",
+		Aliases: []string{"l"},
+		Usage:   "shows cloned gists",
+		Flags: []cli.Flag{
+			profileFlag(&profileName),
+			outputFlag(&output),
+			limitFlag(&limit),
+			pageFlag(&page),
+			sortFlag(&sortOrder),
+			&cli.BoolFlag{
+				Name:        "starred",
+				Usage:       "shows only starred gists",
+				Required:    false,
+				Value:       false,
+				Destination: &starred,
+			},
+			&cli.StringFlag{
+				Name:        "language",
+				Usage:       "shows only gists containing a file of the language",
+				Required:    false,
+				Value:       "",
+				Destination: &language,
+			},
+			&cli.StringFlag{
+				Name:        "visibility",
+				Usage:       "shows only gists of the visibility. available: all, public, secret",
+				Required:    false,
+				Value:       string(visibilityAll),
+				Destination: &visibility,
+			},
+			&cli.StringFlag{
+				Name:        "updated-since",
+				Usage:       "shows only gists updated at or after the time(RFC3339 or yyyy-MM-dd)",
+				Required:    false,
+				Value:       "",
+				Destination: &updatedSince,
+			},
+		},
+		Action: func(context *cli.Context) error {
+			updatedSinceTime, err := parseSince(updatedSince)
+			if err != nil {
+				return err
+			}
+			command := ListCommand{
+				ProfileName:  ProfileName(profileName),
+				OutputFormat: OutputFormat(output),
+				Paging: Paging{
+					Limit: limit,
+					Page:  page,
+				},
+				SortOrder:    SortOrder(sortOrder),
+				StarredOnly:  starred,
+				Language:     language,
+				Visibility:   Visibility(visibility),
+				UpdatedSince: updatedSinceTime,
+				Writer:       os.Stdout,
+			}
+			ctx, err := envValues.NewContext(ProfileFile(*fileFlag))
+			if err != nil {
+				return fmt.Errorf("ListCommand_NewContext: %w", err)
+			}
+			return command.Run(context.Context, ctx)
+		},
+	}
+}
+
+func outputFlag(output *string) cli.Flag {
+	return &cli.StringFlag{
+		Name:        "output",
+		Aliases:     []string{"o"},
+		Usage:       "output format. available: json, xml, yaml, csv, tsv",
+		Required:    false,
+		Value:       "json",
+		Destination: output,
+	}
+}
+
+func limitFlag(limit *int) cli.Flag {
+	return &cli.IntFlag{
+		Name:        "limit",
+		Aliases:     []string{"l"},
+		Usage:       "size of pages. if 0 is given, all gists will be shown",
+		Required:    false,
+		Value:       20,
+		Destination: limit,
+	}
+}
+
+func pageFlag(page *int) cli.Flag {
+	return &cli.IntFlag{
+		Name:        "page",
+		Usage:       "a position of pages",
+		Required:    false,
+		Value:       1,
+		Destination: page,
+	}
+}
+
+func sortFlag(sortOrder *string) cli.Flag {
+	return &cli.StringFlag{
+		Name:        "sort",
+		Usage:       "a sort order of gists. available: pub-desc, pub-asc, id-desc, id-asc",
+		Required:    false,
+		Value:       string(pubDesc),
+		Destination: sortOrder,
+	}
+}
+
+func remoteListCommand(envValues *EnvValues, fileFlag *string) *cli.Command {
+	var profileName string
+	var user string
+	var starred bool
+	var since string
+	var output string
+	return &cli.Command{
+		Name:    "remote-list",
+		Aliases: []string{"rl"},
+		Usage:   "shows gists on GitHub with whether each of them is cloned or not",
+		Flags: []cli.Flag{
+			profileFlag(&profileName),
+			&cli.StringFlag{
+				Name:        "user",
+				Aliases:     []string{"u"},
+				Usage:       "login name of gist owner. if not set, gists of the profile's user will be shown",
+				Required:    false,
+				Value:       "",
+				Destination: &user,
+			},
+			&cli.BoolFlag{
+				Name:        "starred",
+				Usage:       "shows starred gists of the profile's user",
+				Required:    false,
+				Value:       false,
+				Destination: &starred,
+			},
+			&cli.StringFlag{
+				Name:        "since",
+				Usage:       "shows only gists updated after the time(format: 2006-01-02 or 2006-01-02T15:04:05Z)",
+				Required:    false,
+				Value:       "",
+				Destination: &since,
+			},
+			outputFlag(&output),
+		},
+		Action: func(context *cli.Context) error {
+			sinceTime, err := parseSince(since)
+			if err != nil {
+				return fmt.Errorf("RemoteListCommand_ParseSince: %w", err)
+			}
+			command := RemoteListCommand{
+				ProfileName: ProfileName(profileName),
+				GistQuery: GistQuery{
+					User:    user,
+					Starred: starred,
+					Since:   sinceTime,
+				},
+				OutputFormat: OutputFormat(output),
+				Writer:       os.Stdout,
+			}
+			ctx, err := envValues.NewContext(ProfileFile(*fileFlag))
+			if err != nil {
+				return fmt.Errorf("RemoteListCommand_NewContext: %w", err)
+			}
+			return command.Run(context.Context, ctx)
+		},
+	}
+}
+
+func parseSince(since string) (time.Time, error) {
+	if since == "" {
+		return time.Time{}, nil
+	}
+	for _, layout := range []string{time.RFC3339, "2006-01-02"} {
+		t, err := time.Parse(layout, since)
+		if err == nil {
+			return t, nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("invalid time format: %s", since)
+}
+
+func pullCommand(envValues *EnvValues, fileFlag *string) *cli.Command {
+	var profileName string
+	var waitRateLimit bool
+	return &cli.Command{
+		Name:      "pull",
+		Usage:     "updates cloned gists and their metadata",
+		ArgsUsage: "[gist-id or name]",
+		Flags: []cli.Flag{
+			profileFlag(&profileName),
+			waitRateLimitFlag(&waitRateLimit),
+		},
+		Action: func(context *cli.Context) error {
+			err := checkMisplacedFlags(context.Args().Slice())
+			if err != nil {
+				return err
+			}
+			command := PullCommand{
+				ProfileName:   ProfileName(profileName),
+				Target:        context.Args().First(),
+				WaitRateLimit: waitRateLimit,
+				Writer:        os.Stdout,
+			}
+			ctx, err := envValues.NewContext(ProfileFile(*fileFlag))
+			if err != nil {
+				return fmt.Errorf("PullCommand_NewContext: %w", err)
+			}
+			return command.Run(context.Context, ctx)
+		},
+	}
+}
+
+func createCommand(envValues *EnvValues, fileFlag *string) *cli.Command {
+	var profileName string
+	var preferSSH bool
+	var repoName string
+	var description string
+	var public bool
+	var secret bool
+	var stdinName string
+	return &cli.Command{
+		Name:      "create",
+		Usage:     "creates a new gist from files, and clones it",
+		ArgsUsage: "file... (- reads stdin)",
+		Flags: []cli.Flag{
+			profileFlag(&profileName),
+			preferSSHFlag(&preferSSH),
+			repositoryName(&repoName),
+			&cli.StringFlag{
+				Name:        "description",
+				Usage:       "description of the gist",
+				Required:    false,
+				Value:       "",
+				Destination: &description,
+			},
+			&cli.BoolFlag{
+				Name:        "public",
+				Usage:       "creates a public gist",
+				Required:    false,
+				Value:       false,
+				Destination: &public,
+			},
+			&cli.BoolFlag{
+				Name:        "secret",
+				Usage:       "creates a secret gist(default)",
+				Required:    false,
+				Value:       false,
+				Destination: &secret,
+			},
+			&cli.StringFlag{
+				Name:        "stdin-name",
+				Usage:       "file name of the contents read from stdin",
+				Required:    false,
+				Value:       "",
+				Destination: &stdinName,
+			},
+		},
+		Action: func(context *cli.Context) error {
+			if public && secret {
+				return errors.New("public and secret cannot be given at the same time")
+			}
+			command := CreateCommand{
+				ProfileName:    ProfileName(profileName),
+				PreferSSH:      PreferSSHFromBool(preferSSH),
+				RepositoryName: RepositoryName(repoName),
+				Description:    description,
+				Public:         public,
+				Files:          context.Args().Slice(),
+				StdinName:      stdinName,
+				Stdin:          os.Stdin,
+				Writer:         os.Stdout,
+			}
+			ctx, err := envValues.NewContext(ProfileFile(*fileFlag))
+			if err != nil {
+				return fmt.Errorf("CreateCommand_NewContext: %w", err)
+			}
+			return command.Run(context.Context, ctx)
+		},
+	}
+}
+
+func pushCommand(envValues *EnvValues, fileFlag *string) *cli.Command {
+	var profileName string
+	var message string
+	return &cli.Command{
+		Name:      "push",
+		Usage:     "commits all changes of a cloned gist, and pushes them",
+		ArgsUsage: "[gist-id or name] (if not given, gist in the current directory is pushed)",
+		Flags: []cli.Flag{
+			profileFlag(&profileName),
+			&cli.StringFlag{
+				Name:        "message",
+				Aliases:     []string{"m"},
+				Usage:       "commit message. if not given, a message is generated from changed files",
+				Required:    false,
+				Value:       "",
+				Destination: &message,
+			},
+		},
+		Action: func(context *cli.Context) error {
+			err := checkMisplacedFlags(context.Args().Slice())
+			if err != nil {
+				return err
+			}
+			workingDir, err := os.Getwd()
+			if err != nil {
+				return fmt.Errorf("PushCommand_Getwd: %w", err)
+			}
+			command := PushCommand{
+				ProfileName: ProfileName(profileName),
+				Target:      context.Args().First(),
+				WorkingDir:  workingDir,
+				Message:     message,
+				Writer:      os.Stdout,
+			}
+			ctx, err := envValues.NewContext(ProfileFile(*fileFlag))
+			if err != nil {
+				return fmt.Errorf("PushCommand_NewContext: %w", err)
+			}
+			return command.Run(context.Context, ctx)
+		},
+	}
+}
+
+func deleteCommand(envValues *EnvValues, fileFlag *string) *cli.Command {
+	var profileName string
+	var localOnly bool
+	var removeDir bool
+	var yes bool
+	return &cli.Command{
+		Name:      "delete",
+		Usage:     "deletes a gist on GitHub and its metadata",
+		ArgsUsage: "gist-id or name",
+		Flags: []cli.Flag{
+			profileFlag(&profileName),
+			&cli.BoolFlag{
+				Name:        "local-only",
+				Usage:       "removes only the local clone directory and its metadata, keeping the gist on GitHub",
+				Required:    false,
+				Value:       false,
+				Destination: &localOnly,
+			},
+			&cli.BoolFlag{
+				Name:        "remove-dir",
+				Aliases:     []string{"r"},
+				Usage:       "removes the local clone directory as well",
+				Required:    false,
+				Value:       false,
+				Destination: &removeDir,
+			},
+			yesFlag(&yes),
+		},
+		Action: func(context *cli.Context) error {
+			// a misplaced -local-only must not fall back to deletion on GitHub
+			err := checkMisplacedFlags(context.Args().Slice())
+			if err != nil {
+				return err
+			}
+			target := context.Args().First()
+			if target == "" {
+				return errors.New("gist id or name is required")
+			}
+			command := DeleteCommand{
+				ProfileName: ProfileName(profileName),
+				Target:      target,
+				Yes:         yes,
+				LocalOnly:   localOnly,
+				RemoveDir:   removeDir,
+				Stdin:       os.Stdin,
+				Writer:      os.Stdout,
+			}
+			ctx, err := envValues.NewContext(ProfileFile(*fileFlag))
+			if err != nil {
+				return fmt.Errorf("DeleteCommand_NewContext: %w", err)
+			}
+			return command.Run(context.Context, ctx)
+		},
+	}
+}
+
+func waitRateLimitFlag(waitRateLimit *bool) cli.Flag {
+	return &cli.BoolFlag{
+		Name:        "wait-rate-limit",
+		Usage:       "waits until rate limit of GitHub API is reset, instead of aborting remaining gists",
+		Required:    false,
+		Value:       false,
+		Destination: waitRateLimit,
+	}
+}
+
+func yesFlag(yes *bool) cli.Flag {
+	return &cli.BoolFlag{
+		Name:        "yes",
+		Aliases:     []string{"y"},
+		Usage:       "skips confirmation",
+		Required:    false,
+		Value:       false,
+		Destination: yes,
+	}
+}
+
+func editCommand(envValues *EnvValues, fileFlag *string) *cli.Command {
+	var profileName string
+	var description string
+	return &cli.Command{
+		Name:      "edit",
+		Usage:     "changes description and files of a gist",
+		ArgsUsage: "gist-id or name",
+		Flags: []cli.Flag{
+			profileFlag(&profileName),
+			&cli.StringFlag{
+				Name:        "description",
+				Usage:       "new description of the gist",
+				Required:    false,
+				Value:       "",
+				Destination: &description,
+			},
+			&cli.StringSliceFlag{
+				Name:     "file",
+				Usage:    "uploads contents of a local file, given as path or name=path",
+				Required: false,
+			},
+			&cli.StringSliceFlag{
+				Name:     "rename",
+				Usage:    "renames a file, given as old=new",
+				Required: false,
+			},
+			&cli.StringSliceFlag{
+				Name:     "delete",
+				Usage:    "deletes a file",
+				Required: false,
+			},
+		},
+		Action: func(context *cli.Context) error {
+			err := checkMisplacedFlags(context.Args().Slice())
+			if err != nil {
+				return err
+			}
+			target := context.Args().First()
+			if target == "" {
+				return errors.New("gist id or name is required")
+			}
+			command := EditCommand{
+				ProfileName: ProfileName(profileName),
+				Target:      target,
+				Files:       context.StringSlice("file"),
+				Renames:     context.StringSlice("rename"),
+				Deletes:     context.StringSlice("delete"),
+				Writer:      os.Stdout,
+			}
+			if context.IsSet("description") {
+				command.Description = &description
+			}
+			ctx, err := envValues.NewContext(ProfileFile(*fileFlag))
+			if err != nil {
+				return fmt.Errorf("EditCommand_NewContext: %w", err)
+			}
+			return command.Run(context.Context, ctx)
+		},
+	}
+}
+
+func starCommand(envValues *EnvValues, fileFlag *string, unstar bool) *cli.Command {
+	var profileName string
+	name := "star"
+	usage := "stars a gist"
+	if unstar {
+		name = "unstar"
+		usage = "unstars a gist"
+	}
+	return &cli.Command{
+		Name:      name,
+		Usage:     usage,
+		ArgsUsage: "gist-id or name",
+		Flags: []cli.Flag{
+			profileFlag(&profileName),
+		},
+		Action: func(context *cli.Context) error {
+			err := checkMisplacedFlags(context.Args().Slice())
+			if err != nil {
+				return err
+			}
+			target := context.Args().First()
+			if target == "" {
+				return errors.New("gist id or name is required")
+			}
+			command := StarCommand{
+				ProfileName: ProfileName(profileName),
+				Target:      target,
+				Unstar:      unstar,
+				Writer:      os.Stdout,
+			}
+			ctx, err := envValues.NewContext(ProfileFile(*fileFlag))
+			if err != nil {
+				return fmt.Errorf("StarCommand_NewContext: %w", err)
+			}
+			return command.Run(context.Context, ctx)
+		},
+	}
+}
+
+func forkCommand(envValues *EnvValues, fileFlag *string) *cli.Command {
+	var profileName string
+	var preferSSH bool
+	var repoName string
+	return &cli.Command{
+		Name:      "fork",
+		Usage:     "forks a gist under the profile's account, and clones the fork",
+		ArgsUsage: "gist-id",
+		Flags: []cli.Flag{
+			profileFlag(&profileName),
+			preferSSHFlag(&preferSSH),
+			repositoryName(&repoName),
+		},
+		Action: func(context *cli.Context) error {
+			err := checkMisplacedFlags(context.Args().Slice())
+			if err != nil {
+				return err
+			}
+			reference := context.Args().First()
+			if reference == "" {
+				return errors.New("gist id is required")
+			}
+			gistID, err := ParseGistReference(reference)
+			if err != nil {
+				return fmt.Errorf("ParseGistReference: %w", err)
+			}
+			command := ForkCommand{
+				GistID:         *gistID,
+				ProfileName:    ProfileName(profileName),
+				PreferSSH:      PreferSSHFromBool(preferSSH),
+				RepositoryName: RepositoryName(repoName),
+				Writer:         os.Stdout,
+			}
+			ctx, err := envValues.NewContext(ProfileFile(*fileFlag))
+			if err != nil {
+				return fmt.Errorf("ForkCommand_NewContext: %w", err)
+			}
+			return command.Run(context.Context, ctx)
+		},
+	}
+}
+
+func commentsCommand(envValues *EnvValues, fileFlag *string) *cli.Command {
+	return &cli.Command{
+		Name:  "comments",
+		Usage: "manages comments on a gist",
+		Subcommands: []*cli.Command{
+			commentListCommand(envValues, fileFlag),
+			commentAddCommand(envValues, fileFlag),
+			commentEditCommand(envValues, fileFlag),
+			commentDeleteCommand(envValues, fileFlag),
+		},
+	}
+}
+
+func commentListCommand(envValues *EnvValues, fileFlag *string) *cli.Command {
+	var profileName string
+	var output string
+	return &cli.Command{
+		Name:      "list",
+		Aliases:   []string{"ls"},
+		Usage:     "shows comments on a gist",
+		ArgsUsage: "gist-id or name",
+		Flags: []cli.Flag{
+			profileFlag(&profileName),
+			outputFlag(&output),
+		},
+		Action: func(context *cli.Context) error {
+			err := checkMisplacedFlags(context.Args().Slice())
+			if err != nil {
+				return err
+			}
+			target := context.Args().First()
+			if target == "" {
+				return errors.New("gist id or name is required")
+			}
+			command := CommentListCommand{
+				ProfileName:  ProfileName(profileName),
+				Target:       target,
+				OutputFormat: OutputFormat(output),
+				Writer:       os.Stdout,
+			}
+			ctx, err := envValues.NewContext(ProfileFile(*fileFlag))
+			if err != nil {
+				return fmt.Errorf("CommentListCommand_NewContext: %w", err)
+			}
+			return command.Run(context.Context, ctx)
+		},
+	}
+}
+
+func commentAddCommand(envValues *EnvValues, fileFlag *string) *cli.Command {
+	var profileName string
+	return &cli.Command{
+		Name:      "add",
+		Usage:     "adds a comment on a gist. without body, EDITOR is opened",
+		ArgsUsage: "gist-id or name [body (- reads stdin)]",
+		Flags: []cli.Flag{
+			profileFlag(&profileName),
+		},
+		Action: func(context *cli.Context) error {
+			err := checkMisplacedFlags(argsExceptBody(context.Args(), 1))
+			if err != nil {
+				return err
+			}
+			target := context.Args().First()
+			if target == "" {
+				return errors.New("gist id or name is required")
+			}
+			command := CommentAddCommand{
+				ProfileName: ProfileName(profileName),
+				Target:      target,
+				CommentBody: commentBody(context.Args().Get(1)),
+				Writer:      os.Stdout,
+			}
+			ctx, err := envValues.NewContext(ProfileFile(*fileFlag))
+			if err != nil {
+				return fmt.Errorf("CommentAddCommand_NewContext: %w", err)
+			}
+			return command.Run(context.Context, ctx)
+		},
+	}
+}
+
+func commentEditCommand(envValues *EnvValues, fileFlag *string) *cli.Command {
+	var profileName string
+	return &cli.Command{
+		Name:      "edit",
+		Usage:     "replaces body of a comment on a gist. without body, EDITOR is opened",
+		ArgsUsage: "gist-id or name comment-id [body (- reads stdin)]",
+		Flags: []cli.Flag{
+			profileFlag(&profileName),
+		},
+		Action: func(context *cli.Context) error {
+			err := checkMisplacedFlags(argsExceptBody(context.Args(), 2))
+			if err != nil {
+				return err
+			}
+			target := context.Args().First()
+			if target == "" {
+				return errors.New("gist id or name is required")
+			}
+			commentID, err := NewCommentID(context.Args().Get(1))
+			if err != nil {
+				return err
+			}
+			command := CommentEditCommand{
+				ProfileName: ProfileName(profileName),
+				Target:      target,
+				CommentID:   commentID,
+				CommentBody: commentBody(context.Args().Get(2)),
+				Writer:      os.Stdout,
+			}
+			ctx, err := envValues.NewContext(ProfileFile(*fileFlag))
+			if err != nil {
+				return fmt.Errorf("CommentEditCommand_NewContext: %w", err)
+			}
+			return command.Run(context.Context, ctx)
+		},
+	}
+}
+
+func commentDeleteCommand(envValues *EnvValues, fileFlag *string) *cli.Command {
+	var profileName string
+	var yes bool
+	return &cli.Command{
+		Name:      "delete",
+		Usage:     "deletes a comment on a gist",
+		ArgsUsage: "gist-id or name comment-id",
+		Flags: []cli.Flag{
+			profileFlag(&profileName),
+			yesFlag(&yes),
+		},
+		Action: func(context *cli.Context) error {
+			err := checkMisplacedFlags(context.Args().Slice())
+			if err != nil {
+				return err
+			}
+			target := context.Args().First()
+			if target == "" {
+				return errors.New("gist id or name is required")
+			}
+			commentID, err := NewCommentID(context.Args().Get(1))
+			if err != nil {
+				return err
+			}
+			command := CommentDeleteCommand{
+				ProfileName: ProfileName(profileName),
+				Target:      target,
+				CommentID:   commentID,
+				Yes:         yes,
+				Stdin:       os.Stdin,
+				Writer:      os.Stdout,
+			}
+			ctx, err := envValues.NewContext(ProfileFile(*fileFlag))
+			if err != nil {
+				return fmt.Errorf("CommentDeleteCommand_NewContext: %w", err)
+			}
+			return command.Run(context.Context, ctx)
+		},
+	}
+}
+
+// argsExceptBody returns arguments except for the comment body at index, which may start with `-` like a list item.
+func argsExceptBody(args cli.Args, index int) []string {
+	others := make([]string, 0, args.Len())
+	for i, arg := range args.Slice() {
+		if i != index {
+			others = append(others, arg)
+		}
+	}
+	return others
+}
+
+func commentBody(text string) CommentBody {
+	return CommentBody{
+		Text:   text,
+		Stdin:  os.Stdin,
+		Editor: os.Getenv("EDITOR"),
+	}
+}
+
+func historyCommand(envValues *EnvValues, fileFlag *string) *cli.Command {
+	var profileName string
+	var output string
+	return &cli.Command{
+		Name:      "history",
+		Usage:     "shows revisions of a gist",
+		ArgsUsage: "gist-id or name",
+		Flags: []cli.Flag{
+			profileFlag(&profileName),
+			outputFlag(&output),
+		},
+		Action: func(context *cli.Context) error {
+			err := checkMisplacedFlags(context.Args().Slice())
+			if err != nil {
+				return err
+			}
+			target := context.Args().First()
+			if target == "" {
+				return errors.New("gist id or name is required")
+			}
+			command := HistoryCommand{
+				ProfileName:  ProfileName(profileName),
+				Target:       target,
+				OutputFormat: OutputFormat(output),
+				Writer:       os.Stdout,
+			}
+			ctx, err := envValues.NewContext(ProfileFile(*fileFlag))
+			if err != nil {
+				return fmt.Errorf("HistoryCommand_NewContext: %w", err)
+			}
+			return command.Run(context.Context, ctx)
+		},
+	}
+}
+
+func diffCommand(envValues *EnvValues, fileFlag *string) *cli.Command {
+	var profileName string
+	return &cli.Command{
+		Name:      "diff",
+		Usage:     "shows unified diff between revisions of a gist",
+		ArgsUsage: "gist-id or name [rev1] [rev2]",
+		Flags: []cli.Flag{
+			profileFlag(&profileName),
+		},
+		Action: func(context *cli.Context) error {
+			err := checkMisplacedFlags(context.Args().Slice())
+			if err != nil {
+				return err
+			}
+			target := context.Args().First()
+			if target == "" {
+				return errors.New("gist id or name is required")
+			}
+			command := DiffCommand{
+				ProfileName: ProfileName(profileName),
+				Target:      target,
+				From:        context.Args().Get(1),
+				To:          context.Args().Get(2),
+				Writer:      os.Stdout,
+			}
+			ctx, err := envValues.NewContext(ProfileFile(*fileFlag))
+			if err != nil {
+				return fmt.Errorf("DiffCommand_NewContext: %w", err)
+			}
+			return command.Run(context.Context, ctx)
+		},
+	}
+}
+
+func cacheCommand(envValues *EnvValues, fileFlag *string) *cli.Command {
+	var profileName string
+	var all bool
+	return &cli.Command{
+		Name:  "cache",
+		Usage: "manages cached responses of GitHub API",
+		Subcommands: []*cli.Command{
+			{
+				Name:  "clear",
+				Usage: "removes cached responses of GitHub API",
+				Flags: []cli.Flag{
+					profileFlag(&profileName),
+					&cli.BoolFlag{
+						Name:        "all",
+						Aliases:     []string{"a"},
+						Usage:       "removes caches of all profiles",
+						Required:    false,
+						Value:       false,
+						Destination: &all,
+					},
+				},
+				Action: func(context *cli.Context) error {
+					err := checkNoArguments(context)
+					if err != nil {
+						return err
+					}
+					command := CacheClearCommand{
+						ProfileName: ProfileName(profileName),
+						All:         all,
+						Writer:      os.Stdout,
+					}
+					ctx, err := envValues.NewContext(ProfileFile(*fileFlag))
+					if err != nil {
+						return fmt.Errorf("CacheClearCommand_NewContext: %w", err)
+					}
+					return command.Run(context.Context, ctx)
+				},
+			},
+		},
+	}
+}
+
+func repairCommand(envValues *EnvValues, fileFlag *string) *cli.Command {
+	var profileName string
+	return &cli.Command{
+		Name:  "repair",
+		Usage: "records cloned gists which are missing in the metadata file",
+		Flags: []cli.Flag{
+			profileFlag(&profileName),
+		},
+		Action: func(context *cli.Context) error {
+			err := checkNoArguments(context)
+			if err != nil {
+				return err
+			}
+			command := RepairCommand{
+				ProfileName: ProfileName(profileName),
+				Writer:      os.Stdout,
+			}
+			ctx, err := envValues.NewContext(ProfileFile(*fileFlag))
+			if err != nil {
+				return fmt.Errorf("RepairCommand_NewContext: %w", err)
+			}
+			return command.Run(context.Context, ctx)
+		},
+	}
+}
+
+func relayoutCommand(envValues *EnvValues, fileFlag *string) *cli.Command {
+	var profileName string
+	return &cli.Command{
+		Name:      "relayout",
+		Usage:     "moves cloned gists into directories of the layout, and sets the layout to the profile",
+		ArgsUsage: "flat|owner/id|year/month/id|language/id",
+		Flags: []cli.Flag{
+			profileFlag(&profileName),
+		},
+		Action: func(context *cli.Context) error {
+			err := checkMisplacedFlags(context.Args().Slice())
+			if err != nil {
+				return err
+			}
+			layout := context.Args().First()
+			if layout == "" {
+				return errors.New("layout is required")
+			}
+			command := RelayoutCommand{
+				ProfileName: ProfileName(profileName),
+				Layout:      Layout(layout),
+				Writer:      os.Stdout,
+			}
+			ctx, err := envValues.NewContext(ProfileFile(*fileFlag))
+			if err != nil {
+				return fmt.Errorf("RelayoutCommand_NewContext: %w", err)
+			}
+			return command.Run(context.Context, ctx)
+		},
+	}
+}
@@ -0,0 +1,41 @@
+package main
+
+import (
+	"github.com/stretchr/testify/assert"
+	"github.com/urfave/cli/v2"
+	"testing"
+)
+
+func TestCloneTargets_MisplacedFlag(t *testing.T) {
+	_, err := cloneTargets([]string{"0a1b2c3d4e5f", "-ssh"}, "")
+	assert.NotNil(t, err)
+	assert.Contains(t, err.Error(), "flag -ssh is given after arguments")
+
+	gistIDs, err := cloneTargets([]string{"0a1b2c3d4e5f", "https://gist.github.com/user/0a1b2c3d4e5f"}, "")
+	assert.Nil(t, err)
+	assert.Equal(t, []GistID{"0a1b2c3d4e5f"}, gistIDs)
+}
+
+func TestCheckMisplacedFlags(t *testing.T) {
+	assert.Nil(t, checkMisplacedFlags([]string{"main.go", "-"}))
+	assert.NotNil(t, checkMisplacedFlags([]string{"my-snippet", "-yes"}))
+	assert.NotNil(t, checkMisplacedFlags([]string{"my-snippet", "--local-only"}))
+}
+
+func TestCacheClearCommand_UnexpectedArguments(t *testing.T) {
+	envValues := EnvValues{}
+	fileFlag := ""
+	app := cli.App{Commands: []*cli.Command{cacheCommand(&envValues, &fileFlag)}}
+	err := app.Run([]string{"gist", "cache", "clear", "privates"})
+	assert.NotNil(t, err)
+	assert.Contains(t, err.Error(), "unexpected arguments: privates")
+}
+
+func TestRepairCommand_UnexpectedArguments(t *testing.T) {
+	envValues := EnvValues{}
+	fileFlag := ""
+	app := cli.App{Commands: []*cli.Command{repairCommand(&envValues, &fileFlag)}}
+	err := app.Run([]string{"gist", "repair", "privates"})
+	assert.NotNil(t, err)
+	assert.Contains(t, err.Error(), "unexpected arguments: privates")
+}
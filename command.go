@@ -1,6 +1,8 @@
 package main
 
 import (
+	"bytes"
+	"context"
 	"errors"
 	"fmt"
 	"io"
@@ -16,8 +18,13 @@ type Application interface {
 // ProfileFile is the file of profiles to be loaded.
 type ProfileFile string
 
-//NewWriter creates writer of ProfileFile
+//NewWriter creates writer of ProfileFile, which holds lock of the file until Close.
+// Contents are written into the file atomically on Close.
 func (file *ProfileFile) NewWriter() (io.WriteCloser, error) {
+	return file.newWriter()
+}
+
+func (file *ProfileFile) newWriter() (*profileFileWriter, error) {
 	path := string(*file)
 	lastIndex := strings.LastIndex(path, "/")
 	if lastIndex > 0 {
@@ -27,11 +34,64 @@ func (file *ProfileFile) NewWriter() (io.WriteCloser, error) {
 			return nil, fmt.Errorf("ProfileFile_NewWriter_MkdirAll(%s): %w", parent, err)
 		}
 	}
-	writer, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0644)
+	lock, err := lockFile(path)
 	if err != nil {
-		return nil, fmt.Errorf("ProfileFile_NewWriter_OpenFile: %w", err)
+		return nil, fmt.Errorf("ProfileFile_NewWriter_Lock: %w", err)
 	}
-	return writer, nil
+	return &profileFileWriter{path: path, lock: lock}, nil
+}
+
+// profileFileWriter buffers contents of ProfileFile, and replaces the file with them on Close.
+type profileFileWriter struct {
+	bytes.Buffer
+	path string
+	lock *fileLock
+}
+
+func (writer *profileFileWriter) Close() error {
+	if writer.lock == nil {
+		return errors.New("ProfileFile_Writer_Close: already closed")
+	}
+	defer writer.abort()
+	err := writeFileAtomically(writer.path, func(w io.Writer) error {
+		_, err := writer.WriteTo(w)
+		return err
+	})
+	if err != nil {
+		return fmt.Errorf("ProfileFile_Writer_Close: %w", err)
+	}
+	return nil
+}
+
+// abort releases the lock without writing the file.
+func (writer *profileFileWriter) abort() {
+	if writer.lock != nil {
+		_ = writer.lock.Unlock()
+		writer.lock = nil
+	}
+}
+
+// Update replaces profiles in ProfileFile with the result of modify, which is given the latest profiles in the file.
+func (file *ProfileFile) Update(modify func(profiles []Profile) []Profile) error {
+	writer, err := file.newWriter()
+	if err != nil {
+		return fmt.Errorf("ProfileFile_Update_NewWriter: %w", err)
+	}
+	defer writer.abort()
+	profiles, err := file.LoadProfiles()
+	if err != nil {
+		return fmt.Errorf("ProfileFile_Update_LoadProfiles: %w", err)
+	}
+	updated := profileList(modify(profiles))
+	err = updated.saveTo(writer)
+	if err != nil {
+		return fmt.Errorf("ProfileFile_Update_SaveTo: %w", err)
+	}
+	err = writer.Close()
+	if err != nil {
+		return fmt.Errorf("ProfileFile_Update_Close: %w", err)
+	}
+	return nil
 }
 
 // ProfileName is name of profile.
@@ -40,6 +100,28 @@ type ProfileName string
 // GitHubAccessToken is access token of github.com with gist scope.
 type GitHubAccessToken string
 
+// SSHKeyFile is private key file used for git operations via ssh.
+type SSHKeyFile string
+
+// APIBaseURL is base url of GitHub API(e.g. https://github.example.com/api/v3 for GitHub Enterprise).
+type APIBaseURL string
+
+// GistHost is host(and path) of gist git repositories(e.g. github.example.com/gist for GitHub Enterprise).
+type GistHost string
+
+// RequestTimeout is timeout of each request to GitHub API and each attempt of git operations, in format of time.ParseDuration(e.g. 30s).
+type RequestTimeout string
+
+// RetryCount is max number of retries of a request to GitHub API or a git operation failed by network error or server error.
+// Zero means default, and negative value disables retries.
+type RetryCount int
+
+// NameTemplate is a template of directory name of cloned gists in text/template(e.g. `{{.Owner}}-{{.Slug}}`).
+type NameTemplate string
+
+// Layout is how cloned gists are placed under DestinationDir(flat, owner/id, year/month/id or language/id).
+type Layout string
+
 // DestinationDir is destination directory where to clone gist repositories.
 type DestinationDir string
 
@@ -89,6 +171,6 @@ func (ev *EnvValues) DefaultProfileFile() ProfileFile {
 
 // Command represents command being executed by user.
 type Command interface {
-	// Run executes each command.
-	Run(ctx ProfileContext) error
+	// Run executes each command. Cancellation of ctx aborts the command.
+	Run(ctx context.Context, pctx ProfileContext) error
 }
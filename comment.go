@@ -0,0 +1,294 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"strconv"
+	"strings"
+)
+
+// CommentListCommand shows comments on a gist.
+type CommentListCommand struct {
+	ProfileName
+	// Target is id or name of a gist. Only id is available for a gist which is not cloned.
+	Target string
+	OutputFormat
+	Writer io.Writer
+}
+
+// Run command of CommentListCommand
+func (cl *CommentListCommand) Run(ctx context.Context, pctx ProfileContext) error {
+	if cl.Writer == nil {
+		return errors.New("CommentListCommand_Run: writer is not given")
+	}
+	formatter, err := NewFormatter(cl.OutputFormat)
+	if err != nil {
+		return fmt.Errorf("CommentListCommand_Run_NewFormatter: %w", err)
+	}
+	gistID, err := resolveTarget(pctx, cl.ProfileName, cl.Target)
+	if err != nil {
+		return fmt.Errorf("CommentListCommand_Run_ResolveTarget: %w", err)
+	}
+	comments, err := pctx.NewGitHub().ListComments(ctx, gistID, cl.ProfileName)
+	if err != nil {
+		return fmt.Errorf("CommentListCommand_Run_ListComments: %w", err)
+	}
+	records := make(commentRecords, len(comments))
+	for i, comment := range comments {
+		records[i] = CommentEntry{
+			ID:      int64(comment.ID),
+			Author:  comment.User.Login,
+			Created: comment.CreatedAt,
+			Updated: comment.UpdatedAt,
+			Body:    comment.Body,
+		}
+	}
+	err = formatter.Format(cl.Writer, records)
+	if err != nil {
+		return fmt.Errorf("CommentListCommand_Run_Format: %w", err)
+	}
+	return nil
+}
+
+// CommentAddCommand adds a comment on a gist.
+type CommentAddCommand struct {
+	ProfileName
+	// Target is id or name of a gist. Only id is available for a gist which is not cloned.
+	Target string
+	CommentBody
+	Writer io.Writer
+}
+
+// Run command of CommentAddCommand
+func (ca *CommentAddCommand) Run(ctx context.Context, pctx ProfileContext) error {
+	if ca.Writer == nil {
+		return errors.New("CommentAddCommand_Run: writer is not given")
+	}
+	gistID, err := resolveTarget(pctx, ca.ProfileName, ca.Target)
+	if err != nil {
+		return fmt.Errorf("CommentAddCommand_Run_ResolveTarget: %w", err)
+	}
+	body, err := ca.CommentBody.Read("")
+	if err != nil {
+		return fmt.Errorf("CommentAddCommand_Run_ReadBody: %w", err)
+	}
+	comment, err := pctx.NewGitHub().CreateComment(ctx, gistID, body, ca.ProfileName)
+	if err != nil {
+		return fmt.Errorf("CommentAddCommand_Run_CreateComment: %w", err)
+	}
+	_, _ = fmt.Fprintf(ca.Writer, "added comment %d on %s\n", comment.ID, gistID)
+	return nil
+}
+
+// CommentEditCommand replaces body of a comment on a gist.
+type CommentEditCommand struct {
+	ProfileName
+	// Target is id or name of a gist. Only id is available for a gist which is not cloned.
+	Target string
+	CommentID
+	CommentBody
+	Writer io.Writer
+}
+
+// Run command of CommentEditCommand
+func (ce *CommentEditCommand) Run(ctx context.Context, pctx ProfileContext) error {
+	if ce.Writer == nil {
+		return errors.New("CommentEditCommand_Run: writer is not given")
+	}
+	gistID, err := resolveTarget(pctx, ce.ProfileName, ce.Target)
+	if err != nil {
+		return fmt.Errorf("CommentEditCommand_Run_ResolveTarget: %w", err)
+	}
+	gitHub := pctx.NewGitHub()
+	initial := ""
+	if ce.CommentBody.Text == "" {
+		// editor starts with the current body of the comment
+		comments, err := gitHub.ListComments(ctx, gistID, ce.ProfileName)
+		if err != nil {
+			return fmt.Errorf("CommentEditCommand_Run_ListComments: %w", err)
+		}
+		found := false
+		for _, comment := range comments {
+			if comment.ID == ce.CommentID {
+				initial = comment.Body
+				found = true
+				break
+			}
+		}
+		if !found {
+			return fmt.Errorf("no comment found(gist: %s, comment: %d)", gistID, ce.CommentID)
+		}
+	}
+	body, err := ce.CommentBody.Read(initial)
+	if err != nil {
+		return fmt.Errorf("CommentEditCommand_Run_ReadBody: %w", err)
+	}
+	_, err = gitHub.UpdateComment(ctx, gistID, ce.CommentID, body, ce.ProfileName)
+	if err != nil {
+		return fmt.Errorf("CommentEditCommand_Run_UpdateComment: %w", err)
+	}
+	_, _ = fmt.Fprintf(ce.Writer, "updated comment %d on %s\n", ce.CommentID, gistID)
+	return nil
+}
+
+// CommentDeleteCommand deletes a comment on a gist.
+type CommentDeleteCommand struct {
+	ProfileName
+	// Target is id or name of a gist. Only id is available for a gist which is not cloned.
+	Target string
+	CommentID
+	// Yes skips confirmation.
+	Yes    bool
+	Stdin  io.Reader
+	Writer io.Writer
+}
+
+// Run command of CommentDeleteCommand
+func (cd *CommentDeleteCommand) Run(ctx context.Context, pctx ProfileContext) error {
+	if cd.Writer == nil {
+		return errors.New("CommentDeleteCommand_Run: writer is not given")
+	}
+	gistID, err := resolveTarget(pctx, cd.ProfileName, cd.Target)
+	if err != nil {
+		return fmt.Errorf("CommentDeleteCommand_Run_ResolveTarget: %w", err)
+	}
+	if !cd.Yes {
+		ok, err := askConfirmation(cd.Stdin, cd.Writer, fmt.Sprintf("delete comment %d on %s?", cd.CommentID, gistID))
+		if err != nil {
+			return fmt.Errorf("CommentDeleteCommand_Run_Confirm: %w", err)
+		}
+		if !ok {
+			_, _ = fmt.Fprintln(cd.Writer, "canceled")
+			return nil
+		}
+	}
+	err = pctx.NewGitHub().DeleteComment(ctx, gistID, cd.CommentID, cd.ProfileName)
+	if err != nil {
+		return fmt.Errorf("CommentDeleteCommand_Run_DeleteComment: %w", err)
+	}
+	_, _ = fmt.Fprintf(cd.Writer, "deleted comment %d on %s\n", cd.CommentID, gistID)
+	return nil
+}
+
+// NewCommentID parses id of a comment.
+func NewCommentID(id string) (CommentID, error) {
+	value, err := strconv.ParseInt(id, 10, 64)
+	if err != nil || value <= 0 {
+		return 0, fmt.Errorf("invalid comment id: %s", id)
+	}
+	return CommentID(value), nil
+}
+
+// CommentBody is a source of the body of a comment.
+type CommentBody struct {
+	// Text is the body. `-` reads Stdin, and empty opens Editor.
+	Text  string
+	Stdin io.Reader
+	// Editor is a command to edit the body. If empty, `vi` is used.
+	Editor string
+}
+
+// Read returns the body from the source. initial is the contents shown in the editor.
+func (cb *CommentBody) Read(initial string) (string, error) {
+	var body string
+	switch cb.Text {
+	case "-":
+		if cb.Stdin == nil {
+			return "", errors.New("stdin is not given")
+		}
+		contents, err := ioutil.ReadAll(cb.Stdin)
+		if err != nil {
+			return "", fmt.Errorf("CommentBody_ReadStdin: %w", err)
+		}
+		body = string(contents)
+	case "":
+		contents, err := cb.edit(initial)
+		if err != nil {
+			return "", err
+		}
+		body = contents
+	default:
+		body = cb.Text
+	}
+	if strings.TrimSpace(body) == "" {
+		return "", errors.New("comment is empty")
+	}
+	return body, nil
+}
+
+func (cb *CommentBody) edit(initial string) (string, error) {
+	editor := strings.Fields(cb.Editor)
+	if len(editor) == 0 {
+		editor = []string{"vi"}
+	}
+	file, err := ioutil.TempFile("", "gist-comment-*.md")
+	if err != nil {
+		return "", fmt.Errorf("CommentBody_Edit_TempFile: %w", err)
+	}
+	path := file.Name()
+	defer func() { _ = os.Remove(path) }()
+	_, err = file.WriteString(initial)
+	_ = file.Close()
+	if err != nil {
+		return "", fmt.Errorf("CommentBody_Edit_Write: %w", err)
+	}
+
+	command := exec.Command(editor[0], append(editor[1:], path)...)
+	command.Stdin = os.Stdin
+	command.Stdout = os.Stdout
+	command.Stderr = os.Stderr
+	err = command.Run()
+	if err != nil {
+		return "", fmt.Errorf("CommentBody_Edit_RunEditor(%s): %w", cb.Editor, err)
+	}
+	contents, err := ioutil.ReadFile(path)
+	if err != nil {
+		return "", fmt.Errorf("CommentBody_Edit_Read: %w", err)
+	}
+	return string(contents), nil
+}
+
+// resolveTarget returns id of a gist given by id or name recorded in metadata file of the profile.
+func resolveTarget(pctx ProfileContext, profileName ProfileName, target string) (GistID, error) {
+	resolved, err := resolveGistTarget(pctx, profileName, target)
+	if err != nil {
+		return "", err
+	}
+	return resolved.GistID, nil
+}
+
+// CommentEntry is a comment on gist to be shown.
+type CommentEntry struct {
+	ID      int64  `json:"id" xml:"id" yaml:"id"`
+	Author  string `json:"author" xml:"author" yaml:"author"`
+	Created string `json:"created_at" xml:"created_at" yaml:"created_at"`
+	Updated string `json:"updated_at" xml:"updated_at" yaml:"updated_at"`
+	Body    string `json:"body" xml:"body" yaml:"body"`
+}
+
+type commentRecords []CommentEntry
+
+func (records commentRecords) Items() interface{} {
+	return []CommentEntry(records)
+}
+
+func (records commentRecords) XMLNames() (string, string) {
+	return "comments", "comment"
+}
+
+func (records commentRecords) Header() []string {
+	return []string{"id", "author", "created_at", "updated_at", "body"}
+}
+
+func (records commentRecords) Rows() [][]string {
+	rows := make([][]string, len(records))
+	for i, comment := range records {
+		rows[i] = []string{strconv.FormatInt(comment.ID, 10), comment.Author, comment.Created, comment.Updated, comment.Body}
+	}
+	return rows
+}
@@ -0,0 +1,165 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"github.com/stretchr/testify/assert"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+)
+
+func TestCommentListCommand_Run(t *testing.T) {
+	ctx, stop := startTestGitHubServer(func(writer http.ResponseWriter, request *http.Request) {
+		assert.Equal(t, "GET", request.Method)
+		assert.Equal(t, "/gists/aa11/comments", request.URL.Path)
+		_, _ = writer.Write([]byte(`[{"id":1,"body":"LGTM","user":{"login":"reviewer"},"created_at":"2020-01-01T00:00:00Z","updated_at":"2020-01-02T00:00:00Z"}]`))
+	})
+	defer stop()
+	ctx.CurrentProfiles[0].Dir = "build/test/not-existing"
+
+	buffer := new(bytes.Buffer)
+	command := CommentListCommand{ProfileName: "default", Target: "aa11", OutputFormat: "csv", Writer: buffer}
+	err := command.Run(context.Background(), ctx)
+	assert.Nil(t, err)
+	assert.Equal(t, `id,author,created_at,updated_at,body
+1,reviewer,2020-01-01T00:00:00Z,2020-01-02T00:00:00Z,LGTM
+`, buffer.String())
+}
+
+func TestCommentAddCommand_Run_ByName(t *testing.T) {
+	ctx, stop := startTestGitHubServer(func(writer http.ResponseWriter, request *http.Request) {
+		assert.Equal(t, "POST", request.Method)
+		assert.Equal(t, "/gists/aa11/comments", request.URL.Path)
+		bs, _ := ioutil.ReadAll(request.Body)
+		var body commentRequest
+		err := json.Unmarshal(bs, &body)
+		assert.Nil(t, err)
+		assert.Equal(t, "from stdin\n", body.Body)
+		writer.WriteHeader(http.StatusCreated)
+		_, _ = writer.Write([]byte(`{"id":2,"body":"from stdin\n"}`))
+	})
+	defer stop()
+	parent := prepareDeleteTestDir(t)
+	defer func() { _ = os.RemoveAll(parent) }()
+	ctx.CurrentProfiles[0].Dir = DestinationDir(parent)
+
+	buffer := new(bytes.Buffer)
+	command := CommentAddCommand{
+		ProfileName: "default",
+		Target:      "first",
+		CommentBody: CommentBody{Text: "-", Stdin: strings.NewReader("from stdin\n")},
+		Writer:      buffer,
+	}
+	err := command.Run(context.Background(), ctx)
+	assert.Nil(t, err)
+	assert.Equal(t, "added comment 2 on aa11\n", buffer.String())
+}
+
+func TestCommentEditCommand_Run_Editor(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("editor script is not available on windows")
+	}
+	ctx, stop := startTestGitHubServer(func(writer http.ResponseWriter, request *http.Request) {
+		if request.Method == "GET" {
+			_, _ = writer.Write([]byte(`[{"id":1,"body":"old"},{"id":3,"body":"other"}]`))
+			return
+		}
+		assert.Equal(t, "PATCH", request.Method)
+		assert.Equal(t, "/gists/aa11/comments/1", request.URL.Path)
+		bs, _ := ioutil.ReadAll(request.Body)
+		var body commentRequest
+		err := json.Unmarshal(bs, &body)
+		assert.Nil(t, err)
+		assert.Equal(t, "old edited\n", body.Body)
+		_, _ = writer.Write([]byte(`{"id":1,"body":"old edited\n"}`))
+	})
+	defer stop()
+	ctx.CurrentProfiles[0].Dir = "build/test/not-existing"
+	parent, err := ioutil.TempDir("", "comment-test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() { _ = os.RemoveAll(parent) }()
+	editor := filepath.Join(parent, "editor.sh")
+	err = ioutil.WriteFile(editor, []byte("#!/bin/sh\necho \" edited\" >> \"$1\"\n"), 0755)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	buffer := new(bytes.Buffer)
+	command := CommentEditCommand{
+		ProfileName: "default",
+		Target:      "aa11",
+		CommentID:   1,
+		CommentBody: CommentBody{Editor: editor},
+		Writer:      buffer,
+	}
+	err = command.Run(context.Background(), ctx)
+	assert.Nil(t, err)
+	assert.Equal(t, "updated comment 1 on aa11\n", buffer.String())
+}
+
+func TestCommentEditCommand_Run_NotFound(t *testing.T) {
+	ctx, stop := startTestGitHubServer(func(writer http.ResponseWriter, request *http.Request) {
+		assert.Equal(t, "GET", request.Method)
+		_, _ = writer.Write([]byte(`[]`))
+	})
+	defer stop()
+	ctx.CurrentProfiles[0].Dir = "build/test/not-existing"
+
+	command := CommentEditCommand{ProfileName: "default", Target: "aa11", CommentID: 1, Writer: new(bytes.Buffer)}
+	err := command.Run(context.Background(), ctx)
+	assert.NotNil(t, err)
+}
+
+func TestCommentDeleteCommand_Run(t *testing.T) {
+	deleted := false
+	ctx, stop := startTestGitHubServer(func(writer http.ResponseWriter, request *http.Request) {
+		assert.Equal(t, "DELETE", request.Method)
+		assert.Equal(t, "/gists/aa11/comments/5", request.URL.Path)
+		deleted = true
+		writer.WriteHeader(http.StatusNoContent)
+	})
+	defer stop()
+	ctx.CurrentProfiles[0].Dir = "build/test/not-existing"
+
+	buffer := new(bytes.Buffer)
+	command := CommentDeleteCommand{
+		ProfileName: "default",
+		Target:      "aa11",
+		CommentID:   5,
+		Stdin:       strings.NewReader("y\n"),
+		Writer:      buffer,
+	}
+	err := command.Run(context.Background(), ctx)
+	assert.Nil(t, err)
+	assert.True(t, deleted)
+	assert.Equal(t, "delete comment 5 on aa11? [y/N]: deleted comment 5 on aa11\n", buffer.String())
+}
+
+func TestCommentBody_Read(t *testing.T) {
+	body := CommentBody{Text: "LGTM"}
+	text, err := body.Read("")
+	assert.Nil(t, err)
+	assert.Equal(t, "LGTM", text)
+
+	body = CommentBody{Text: "-", Stdin: strings.NewReader("  \n")}
+	_, err = body.Read("")
+	assert.NotNil(t, err)
+}
+
+func TestNewCommentID(t *testing.T) {
+	id, err := NewCommentID("1234")
+	assert.Nil(t, err)
+	assert.Equal(t, CommentID(1234), id)
+	_, err = NewCommentID("abc")
+	assert.NotNil(t, err)
+	_, err = NewCommentID("")
+	assert.NotNil(t, err)
+}
@@ -1,6 +1,10 @@
 package main
 
-import "fmt"
+import (
+	"fmt"
+	"strings"
+	"time"
+)
 
 // NewContext returns ProfileContext created by the Environmental variables.
 func (ev *EnvValues) NewContext(file ProfileFile) (ProfileContext, error) {
@@ -53,3 +57,107 @@ func (context *ProfileContext) Dir(profileName ProfileName) (DestinationDir, err
 	}
 	return "", fmt.Errorf("no profile found(name = %s)", profileName)
 }
+
+// SSHKeyFile returns SSHKeyFile of given profile. Leading `~/` is replaced with user home.
+// If the profile has no key file, empty SSHKeyFile will be returned.
+func (context *ProfileContext) SSHKeyFile(profileName ProfileName) (SSHKeyFile, error) {
+	for _, profile := range context.CurrentProfiles {
+		if profile.Name == profileName {
+			keyFile := string(profile.SSHKeyFile)
+			if strings.HasPrefix(keyFile, "~/") {
+				keyFile = fmt.Sprintf("%s/%s", context.EnvValues.UserHome, keyFile[2:])
+			}
+			return SSHKeyFile(keyFile), nil
+		}
+	}
+	return "", fmt.Errorf("no profile found(name = %s)", profileName)
+}
+
+// APIBaseURL returns APIBaseURL of given profile. If the profile has no url, api.github.com is used.
+func (context *ProfileContext) APIBaseURL(profileName ProfileName) (APIBaseURL, error) {
+	for _, profile := range context.CurrentProfiles {
+		if profile.Name == profileName {
+			baseURL := profile.APIBaseURL
+			if baseURL == "" {
+				baseURL = APIBaseURL(githubAPIBaseURL)
+			}
+			return APIBaseURL(strings.TrimSuffix(string(baseURL), "/")), nil
+		}
+	}
+	return "", fmt.Errorf("no profile found(name = %s)", profileName)
+}
+
+// GistHost returns GistHost of given profile. If the profile has no host, gist.github.com is used.
+func (context *ProfileContext) GistHost(profileName ProfileName) (GistHost, error) {
+	for _, profile := range context.CurrentProfiles {
+		if profile.Name == profileName {
+			host := profile.GistHost
+			if host == "" {
+				host = defaultGistHost
+			}
+			return GistHost(strings.Trim(string(host), "/")), nil
+		}
+	}
+	return "", fmt.Errorf("no profile found(name = %s)", profileName)
+}
+
+var defaultTimeout = 30 * time.Second
+var defaultRetries = 3
+
+// Timeout returns timeout of each request to GitHub API and each attempt of git operations for given profile. If the profile has no timeout, 30 seconds is used.
+func (context *ProfileContext) Timeout(profileName ProfileName) (time.Duration, error) {
+	for _, profile := range context.CurrentProfiles {
+		if profile.Name == profileName {
+			if profile.Timeout == "" {
+				return defaultTimeout, nil
+			}
+			timeout, err := time.ParseDuration(string(profile.Timeout))
+			if err != nil {
+				return 0, fmt.Errorf("invalid timeout of profile(name = %s): %w", profileName, err)
+			}
+			return timeout, nil
+		}
+	}
+	return 0, fmt.Errorf("no profile found(name = %s)", profileName)
+}
+
+// Retries returns max number of retries for given profile. If the profile has no retries, 3 is used.
+// Negative value in the profile disables retries.
+func (context *ProfileContext) Retries(profileName ProfileName) (int, error) {
+	for _, profile := range context.CurrentProfiles {
+		if profile.Name == profileName {
+			switch {
+			case profile.Retries == 0:
+				return defaultRetries, nil
+			case profile.Retries < 0:
+				return 0, nil
+			}
+			return int(profile.Retries), nil
+		}
+	}
+	return 0, fmt.Errorf("no profile found(name = %s)", profileName)
+}
+
+// NameTemplate returns NameTemplate of given profile. If the profile has no template, empty NameTemplate will be returned.
+func (context *ProfileContext) NameTemplate(profileName ProfileName) (NameTemplate, error) {
+	for _, profile := range context.CurrentProfiles {
+		if profile.Name == profileName {
+			return profile.NameTemplate, nil
+		}
+	}
+	return "", fmt.Errorf("no profile found(name = %s)", profileName)
+}
+
+// Layout returns Layout of given profile. If the profile has no layout, flat is used.
+func (context *ProfileContext) Layout(profileName ProfileName) (Layout, error) {
+	for _, profile := range context.CurrentProfiles {
+		if profile.Name == profileName {
+			layout, err := NewLayout(string(profile.Layout))
+			if err != nil {
+				return "", fmt.Errorf("invalid layout of profile(name = %s): %w", profileName, err)
+			}
+			return layout, nil
+		}
+	}
+	return "", fmt.Errorf("no profile found(name = %s)", profileName)
+}
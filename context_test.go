@@ -3,6 +3,7 @@ package main
 import (
 	"github.com/stretchr/testify/assert"
 	"testing"
+	"time"
 )
 
 func TestProfileContext_Token(t *testing.T) {
@@ -130,3 +131,136 @@ func TestProfileContext_Dir_From_Env(t *testing.T) {
 	assert.Nil(t, err)
 	assert.Equal(t, DestinationDir("/users/ec2-user/gist/privates"), destinationDir)
 }
+
+func TestProfileContext_SSHKeyFile(t *testing.T) {
+	context := ProfileContext{
+		EnvValues: EnvValues{
+			UserHome: "/users/ec2-user",
+		},
+		CurrentProfiles: []Profile{
+			{
+				Name:       "default",
+				SSHKeyFile: "~/.ssh/id_gist",
+			},
+			{
+				Name:       "privates",
+				SSHKeyFile: "/keys/id_privates",
+			},
+			{
+				Name: "agent",
+			},
+		},
+	}
+	keyFile, err := context.SSHKeyFile("default")
+	assert.Nil(t, err)
+	assert.Equal(t, SSHKeyFile("/users/ec2-user/.ssh/id_gist"), keyFile)
+	keyFile, err = context.SSHKeyFile("privates")
+	assert.Nil(t, err)
+	assert.Equal(t, SSHKeyFile("/keys/id_privates"), keyFile)
+	keyFile, err = context.SSHKeyFile("agent")
+	assert.Nil(t, err)
+	assert.Equal(t, SSHKeyFile(""), keyFile)
+	_, err = context.SSHKeyFile("app")
+	assert.NotNil(t, err)
+}
+
+func TestProfileContext_APIBaseURL(t *testing.T) {
+	context := ProfileContext{
+		CurrentProfiles: []Profile{
+			{
+				Name:       "enterprise",
+				APIBaseURL: "https://github.example.com/api/v3/",
+			},
+			{
+				Name: "default",
+			},
+		},
+	}
+	baseURL, err := context.APIBaseURL("enterprise")
+	assert.Nil(t, err)
+	assert.Equal(t, APIBaseURL("https://github.example.com/api/v3"), baseURL)
+	baseURL, err = context.APIBaseURL("default")
+	assert.Nil(t, err)
+	assert.Equal(t, APIBaseURL("https://api.github.com"), baseURL)
+	_, err = context.APIBaseURL("app")
+	assert.NotNil(t, err)
+}
+
+func TestProfileContext_GistHost(t *testing.T) {
+	context := ProfileContext{
+		CurrentProfiles: []Profile{
+			{
+				Name:     "enterprise",
+				GistHost: "github.example.com/gist/",
+			},
+			{
+				Name: "default",
+			},
+		},
+	}
+	host, err := context.GistHost("enterprise")
+	assert.Nil(t, err)
+	assert.Equal(t, GistHost("github.example.com/gist"), host)
+	host, err = context.GistHost("default")
+	assert.Nil(t, err)
+	assert.Equal(t, GistHost("gist.github.com"), host)
+	_, err = context.GistHost("app")
+	assert.NotNil(t, err)
+}
+
+func TestProfileContext_Timeout(t *testing.T) {
+	context := ProfileContext{
+		CurrentProfiles: []Profile{
+			{
+				Name:    "slow",
+				Timeout: "2m",
+			},
+			{
+				Name:    "invalid",
+				Timeout: "2 minutes",
+			},
+			{
+				Name: "default",
+			},
+		},
+	}
+	timeout, err := context.Timeout("slow")
+	assert.Nil(t, err)
+	assert.Equal(t, 2*time.Minute, timeout)
+	timeout, err = context.Timeout("default")
+	assert.Nil(t, err)
+	assert.Equal(t, 30*time.Second, timeout)
+	_, err = context.Timeout("invalid")
+	assert.NotNil(t, err)
+	_, err = context.Timeout("app")
+	assert.NotNil(t, err)
+}
+
+func TestProfileContext_Retries(t *testing.T) {
+	context := ProfileContext{
+		CurrentProfiles: []Profile{
+			{
+				Name:    "many",
+				Retries: 5,
+			},
+			{
+				Name:    "never",
+				Retries: -1,
+			},
+			{
+				Name: "default",
+			},
+		},
+	}
+	retries, err := context.Retries("many")
+	assert.Nil(t, err)
+	assert.Equal(t, 5, retries)
+	retries, err = context.Retries("never")
+	assert.Nil(t, err)
+	assert.Equal(t, 0, retries)
+	retries, err = context.Retries("default")
+	assert.Nil(t, err)
+	assert.Equal(t, 3, retries)
+	_, err = context.Retries("app")
+	assert.NotNil(t, err)
+}
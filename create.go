@@ -0,0 +1,100 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"path/filepath"
+)
+
+// CreateCommand creates a new gist from local files, then clones it.
+type CreateCommand struct {
+	ProfileName
+	PreferSSH
+	RepositoryName
+	Description string
+	Public      bool
+	// Files are paths of files to be uploaded. `-` means Stdin.
+	Files []string
+	// StdinName is file name of the contents read from Stdin.
+	StdinName string
+	Stdin     io.Reader
+	Writer    io.Writer
+}
+
+// Run command of CreateCommand
+func (cc *CreateCommand) Run(ctx context.Context, pctx ProfileContext) error {
+	if cc.Writer == nil {
+		return errors.New("CreateCommand_Run: writer is not given")
+	}
+	newGist, err := cc.NewGist()
+	if err != nil {
+		return fmt.Errorf("CreateCommand_Run_NewGist: %w", err)
+	}
+	gitHub := pctx.NewGitHub()
+	gist, err := gitHub.CreateGist(ctx, *newGist, cc.ProfileName)
+	if err != nil {
+		return fmt.Errorf("CreateCommand_Run_CreateGist: %w", err)
+	}
+	_, _ = fmt.Fprintln(cc.Writer, "created", gist.ID)
+	command := CloneCommand{
+		GistID:         GistID(gist.ID),
+		ProfileName:    cc.ProfileName,
+		PreferSSH:      cc.PreferSSH,
+		RepositoryName: cc.RepositoryName,
+	}
+	err = command.Run(ctx, pctx)
+	if err != nil {
+		return fmt.Errorf("CreateCommand_Run_Clone(%s): %w", gist.ID, err)
+	}
+	return nil
+}
+
+// NewGist reads files and creates request of gist.
+func (cc *CreateCommand) NewGist() (*NewGist, error) {
+	if len(cc.Files) == 0 {
+		return nil, errors.New("at least one file is required")
+	}
+	files := make(map[string]GistContent, len(cc.Files))
+	for _, path := range cc.Files {
+		name, contents, err := cc.readFile(path)
+		if err != nil {
+			return nil, err
+		}
+		if _, exists := files[name]; exists {
+			return nil, fmt.Errorf("file name %s is duplicated", name)
+		}
+		if len(contents) == 0 {
+			return nil, fmt.Errorf("file %s is empty", name)
+		}
+		files[name] = GistContent{Content: string(contents)}
+	}
+	return &NewGist{
+		Description: cc.Description,
+		Public:      cc.Public,
+		Files:       files,
+	}, nil
+}
+
+func (cc *CreateCommand) readFile(path string) (string, []byte, error) {
+	if path == "-" {
+		if cc.StdinName == "" {
+			return "", nil, errors.New("file name for stdin is required")
+		}
+		if cc.Stdin == nil {
+			return "", nil, errors.New("stdin is not given")
+		}
+		contents, err := ioutil.ReadAll(cc.Stdin)
+		if err != nil {
+			return "", nil, fmt.Errorf("CreateCommand_ReadStdin: %w", err)
+		}
+		return cc.StdinName, contents, nil
+	}
+	contents, err := ioutil.ReadFile(path)
+	if err != nil {
+		return "", nil, fmt.Errorf("CreateCommand_ReadFile(%s): %w", path, err)
+	}
+	return filepath.Base(path), contents, nil
+}
@@ -0,0 +1,125 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"github.com/stretchr/testify/assert"
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"testing"
+)
+
+func TestCreateCommand_NewGist(t *testing.T) {
+	command := CreateCommand{
+		Description: "test gist",
+		Public:      true,
+		Files:       []string{"testdata/profile.yml", "-"},
+		StdinName:   "hello.txt",
+		Stdin:       strings.NewReader("hello"),
+	}
+	newGist, err := command.NewGist()
+	assert.Nil(t, err)
+	assert.Equal(t, "test gist", newGist.Description)
+	assert.True(t, newGist.Public)
+	assert.Equal(t, 2, len(newGist.Files))
+	assert.Equal(t, GistContent{Content: "hello"}, newGist.Files["hello.txt"])
+	assert.True(t, strings.HasPrefix(newGist.Files["profile.yml"].Content, "- profile: default"))
+}
+
+func TestCreateCommand_NewGist_NoFiles(t *testing.T) {
+	command := CreateCommand{}
+	_, err := command.NewGist()
+	assert.NotNil(t, err)
+}
+
+func TestCreateCommand_NewGist_StdinWithoutName(t *testing.T) {
+	command := CreateCommand{
+		Files: []string{"-"},
+		Stdin: strings.NewReader("hello"),
+	}
+	_, err := command.NewGist()
+	assert.NotNil(t, err)
+}
+
+func TestCreateCommand_NewGist_DuplicatedName(t *testing.T) {
+	command := CreateCommand{
+		Files: []string{"testdata/profile.yml", "./testdata/profile.yml"},
+	}
+	_, err := command.NewGist()
+	assert.NotNil(t, err)
+}
+
+func TestCreateCommand_NewGist_EmptyFile(t *testing.T) {
+	command := CreateCommand{
+		Files: []string{"testdata/test.yml"},
+	}
+	_, err := command.NewGist()
+	assert.NotNil(t, err)
+}
+
+func TestGitHubImpl_CreateGist(t *testing.T) {
+	ctx, stop := startTestGitHubServer(func(writer http.ResponseWriter, request *http.Request) {
+		assert.Equal(t, "POST", request.Method)
+		assert.Equal(t, "/gists", request.URL.Path)
+		assert.Equal(t, "application/json", request.Header.Get("content-type"))
+		bytes, _ := ioutil.ReadAll(request.Body)
+		var newGist NewGist
+		err := json.Unmarshal(bytes, &newGist)
+		assert.Nil(t, err)
+		assert.Equal(t, "hello", newGist.Files["hello.txt"].Content)
+		assert.False(t, newGist.Public)
+		writer.WriteHeader(http.StatusCreated)
+		_, _ = writer.Write([]byte(`{"id":"aa11","owner":{"login":"test-user"}}`))
+	})
+	defer stop()
+
+	gist, err := ctx.NewGitHub().CreateGist(context.Background(), NewGist{
+		Files: map[string]GistContent{"hello.txt": {Content: "hello"}},
+	}, "default")
+	assert.Nil(t, err)
+	assert.Equal(t, "aa11", gist.ID)
+}
+
+func TestGitHubImpl_CreateGist_Error(t *testing.T) {
+	ctx, stop := startTestGitHubServer(func(writer http.ResponseWriter, request *http.Request) {
+		writer.WriteHeader(http.StatusUnprocessableEntity)
+	})
+	defer stop()
+
+	gist, err := ctx.NewGitHub().CreateGist(context.Background(), NewGist{}, "default")
+	assert.NotNil(t, err)
+	assert.Nil(t, gist)
+}
+
+func TestCreateCommand_Run_WritesCreatedGist(t *testing.T) {
+	ctx, stop := startTestGitHubServer(func(writer http.ResponseWriter, request *http.Request) {
+		if request.Method == "POST" {
+			writer.WriteHeader(http.StatusCreated)
+			_, _ = writer.Write([]byte(`{"id":"aa11","owner":{"login":"test-user"}}`))
+			return
+		}
+		// clone of the created gist fails
+		writer.WriteHeader(http.StatusNotFound)
+	})
+	defer stop()
+
+	buffer := new(bytes.Buffer)
+	command := CreateCommand{
+		ProfileName: "default",
+		Files:       []string{"-"},
+		StdinName:   "hello.txt",
+		Stdin:       strings.NewReader("hello"),
+		Writer:      buffer,
+	}
+	err := command.Run(context.Background(), ctx)
+	assert.NotNil(t, err)
+	assert.Equal(t, "created aa11\n", buffer.String())
+}
+
+func TestCreateCommand_Run_NoWriter(t *testing.T) {
+	command := CreateCommand{Files: []string{"-"}}
+	err := command.Run(context.Background(), ProfileContext{})
+	assert.NotNil(t, err)
+}
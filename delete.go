@@ -0,0 +1,107 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+)
+
+// DeleteCommand deletes a gist on GitHub, and its entry in metadata file.
+type DeleteCommand struct {
+	ProfileName
+	// Target is id or name of a gist. Only id is available for a gist which is not cloned.
+	Target string
+	// Yes skips confirmation.
+	Yes bool
+	// LocalOnly removes only the local clone directory and its metadata, keeping the gist on GitHub.
+	LocalOnly bool
+	// RemoveDir removes the local clone directory as well.
+	RemoveDir bool
+	Stdin     io.Reader
+	Writer    io.Writer
+}
+
+// Run command of DeleteCommand
+func (dc *DeleteCommand) Run(ctx context.Context, pctx ProfileContext) error {
+	if dc.Writer == nil {
+		return errors.New("DeleteCommand_Run: writer is not given")
+	}
+	target, err := resolveGistTarget(pctx, dc.ProfileName, dc.Target)
+	if err != nil {
+		return fmt.Errorf("DeleteCommand_Run_ResolveTarget: %w", err)
+	}
+	gistID := target.GistID
+	if target.Metadata == nil && dc.LocalOnly {
+		return fmt.Errorf("no cloned gist found(id or name = %s)", dc.Target)
+	}
+
+	if !dc.Yes {
+		ok, err := askConfirmation(dc.Stdin, dc.Writer, fmt.Sprintf("delete gist %s(%s)?", gistID, dc.scope(target.Metadata != nil)))
+		if err != nil {
+			return fmt.Errorf("DeleteCommand_Run_Confirm: %w", err)
+		}
+		if !ok {
+			_, _ = fmt.Fprintln(dc.Writer, "canceled")
+			return nil
+		}
+	}
+
+	if !dc.LocalOnly {
+		gitHub := pctx.NewGitHub()
+		err = gitHub.DeleteGist(ctx, gistID, dc.ProfileName)
+		if err != nil {
+			return fmt.Errorf("DeleteCommand_Run_DeleteGist: %w", err)
+		}
+		_, _ = fmt.Fprintf(dc.Writer, "deleted %s on GitHub\n", gistID)
+	}
+	if target.Metadata == nil {
+		return nil
+	}
+	if dc.LocalOnly || dc.RemoveDir {
+		directory, err := target.Resolve(target.Metadata.RelativePath())
+		if err != nil {
+			return fmt.Errorf("DeleteCommand_Run_ResolveRepository: %w", err)
+		}
+		err = os.RemoveAll(directory)
+		if err != nil {
+			return fmt.Errorf("DeleteCommand_Run_RemoveAll: %w", err)
+		}
+		_, _ = fmt.Fprintf(dc.Writer, "removed %s\n", directory)
+	}
+	target.MetadataIndex.Delete(target.Metadata.ID)
+	err = target.MetadataIndex.Save()
+	if err != nil {
+		return fmt.Errorf("DeleteCommand_Run_SaveMetadata: %w", err)
+	}
+	return nil
+}
+
+func (dc *DeleteCommand) scope(cloned bool) string {
+	switch {
+	case dc.LocalOnly:
+		return "local clone only"
+	case cloned && dc.RemoveDir:
+		return "GitHub and local clone"
+	case cloned:
+		return "GitHub, local clone is kept"
+	}
+	return "GitHub"
+}
+
+// askConfirmation asks yes or no with message, and returns true if the answer is y or yes.
+func askConfirmation(reader io.Reader, writer io.Writer, message string) (bool, error) {
+	if reader == nil {
+		return false, errors.New("no input to confirm, use -yes to skip confirmation")
+	}
+	_, _ = fmt.Fprintf(writer, "%s [y/N]: ", message)
+	line, err := bufio.NewReader(reader).ReadString('\n')
+	if err != nil && !errors.Is(err, io.EOF) {
+		return false, err
+	}
+	answer := strings.ToLower(strings.TrimSpace(line))
+	return answer == "y" || answer == "yes", nil
+}
@@ -0,0 +1,144 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"github.com/stretchr/testify/assert"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func prepareDeleteTestDir(t *testing.T) string {
+	parent, err := ioutil.TempDir("", "delete-test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, md := range []RepositoryMetadata{{ID: "aa11", Name: "first"}, {ID: "bb22"}} {
+		err = os.MkdirAll(filepath.Join(parent, md.DirName()), 0755)
+		if err != nil {
+			t.Fatal(err)
+		}
+		saveTestMetadata(t, filepath.Join(parent, ".gist"), md)
+	}
+	return parent
+}
+
+func TestDeleteCommand_Run_RemoteAndDir(t *testing.T) {
+	deleted := false
+	ctx, stop := startTestGitHubServer(func(writer http.ResponseWriter, request *http.Request) {
+		assert.Equal(t, "DELETE", request.Method)
+		assert.Equal(t, "/gists/aa11", request.URL.Path)
+		deleted = true
+		writer.WriteHeader(http.StatusNoContent)
+	})
+	defer stop()
+	parent := prepareDeleteTestDir(t)
+	defer func() { _ = os.RemoveAll(parent) }()
+	ctx.CurrentProfiles[0].Dir = DestinationDir(parent)
+
+	buffer := new(bytes.Buffer)
+	command := DeleteCommand{
+		ProfileName: "default",
+		Target:      "first",
+		RemoveDir:   true,
+		Stdin:       strings.NewReader("y\n"),
+		Writer:      buffer,
+	}
+	err := command.Run(context.Background(), ctx)
+	assert.Nil(t, err)
+	assert.True(t, deleted)
+	_, err = os.Stat(filepath.Join(parent, "first"))
+	assert.True(t, os.IsNotExist(err))
+	items, err := LoadMetadataFrom(filepath.Join(parent, ".gist"))
+	assert.Nil(t, err)
+	assert.Equal(t, []RepositoryMetadata{{ID: "bb22"}}, items)
+	assert.True(t, strings.HasPrefix(buffer.String(), "delete gist aa11(GitHub and local clone)? [y/N]: "), buffer.String())
+}
+
+func TestDeleteCommand_Run_KeepsDir(t *testing.T) {
+	ctx, stop := startTestGitHubServer(func(writer http.ResponseWriter, request *http.Request) {
+		writer.WriteHeader(http.StatusNoContent)
+	})
+	defer stop()
+	parent := prepareDeleteTestDir(t)
+	defer func() { _ = os.RemoveAll(parent) }()
+	ctx.CurrentProfiles[0].Dir = DestinationDir(parent)
+
+	command := DeleteCommand{ProfileName: "default", Target: "bb22", Yes: true, Writer: new(bytes.Buffer)}
+	err := command.Run(context.Background(), ctx)
+	assert.Nil(t, err)
+	_, err = os.Stat(filepath.Join(parent, "bb22"))
+	assert.Nil(t, err)
+	items, err := LoadMetadataFrom(filepath.Join(parent, ".gist"))
+	assert.Nil(t, err)
+	assert.Equal(t, []RepositoryMetadata{{ID: "aa11", Name: "first"}}, items)
+}
+
+func TestDeleteCommand_Run_LocalOnly(t *testing.T) {
+	ctx, stop := startTestGitHubServer(func(writer http.ResponseWriter, request *http.Request) {
+		assert.Fail(t, "GitHub should not be called")
+	})
+	defer stop()
+	parent := prepareDeleteTestDir(t)
+	defer func() { _ = os.RemoveAll(parent) }()
+	ctx.CurrentProfiles[0].Dir = DestinationDir(parent)
+
+	command := DeleteCommand{ProfileName: "default", Target: "aa11", LocalOnly: true, Yes: true, Writer: new(bytes.Buffer)}
+	err := command.Run(context.Background(), ctx)
+	assert.Nil(t, err)
+	_, err = os.Stat(filepath.Join(parent, "first"))
+	assert.True(t, os.IsNotExist(err))
+	items, err := LoadMetadataFrom(filepath.Join(parent, ".gist"))
+	assert.Nil(t, err)
+	assert.Equal(t, []RepositoryMetadata{{ID: "bb22"}}, items)
+}
+
+func TestDeleteCommand_Run_Canceled(t *testing.T) {
+	ctx, stop := startTestGitHubServer(func(writer http.ResponseWriter, request *http.Request) {
+		assert.Fail(t, "GitHub should not be called")
+	})
+	defer stop()
+	parent := prepareDeleteTestDir(t)
+	defer func() { _ = os.RemoveAll(parent) }()
+	ctx.CurrentProfiles[0].Dir = DestinationDir(parent)
+
+	buffer := new(bytes.Buffer)
+	command := DeleteCommand{ProfileName: "default", Target: "aa11", Stdin: strings.NewReader("\n"), Writer: buffer}
+	err := command.Run(context.Background(), ctx)
+	assert.Nil(t, err)
+	assert.True(t, strings.HasSuffix(buffer.String(), "canceled\n"))
+	items, err := LoadMetadataFrom(filepath.Join(parent, ".gist"))
+	assert.Nil(t, err)
+	assert.Equal(t, 2, len(items))
+}
+
+func TestDeleteCommand_Run_LocalOnlyNotCloned(t *testing.T) {
+	ctx := ProfileContext{
+		CurrentProfiles: []Profile{{Name: "default", Dir: "build/test/not-existing"}},
+	}
+	command := DeleteCommand{ProfileName: "default", Target: "cc33", LocalOnly: true, Yes: true, Writer: new(bytes.Buffer)}
+	err := command.Run(context.Background(), ctx)
+	assert.NotNil(t, err)
+}
+
+func TestGitHubImpl_DeleteGist_Error(t *testing.T) {
+	ctx, stop := startTestGitHubServer(func(writer http.ResponseWriter, request *http.Request) {
+		writer.WriteHeader(http.StatusNotFound)
+	})
+	defer stop()
+
+	err := ctx.NewGitHub().DeleteGist(context.Background(), "aa11", "default")
+	assert.NotNil(t, err)
+}
+
+func TestAskConfirmation(t *testing.T) {
+	for answer, expected := range map[string]bool{"y\n": true, "YES\n": true, "n\n": false, "": false, "yes": true} {
+		ok, err := askConfirmation(strings.NewReader(answer), new(bytes.Buffer), "delete?")
+		assert.Nil(t, err)
+		assert.Equal(t, expected, ok, answer)
+	}
+}
@@ -0,0 +1,302 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/sergi/go-diff/diffmatchpatch"
+	"gopkg.in/src-d/go-git.v4"
+	"gopkg.in/src-d/go-git.v4/plumbing"
+	"gopkg.in/src-d/go-git.v4/plumbing/filemode"
+	fdiff "gopkg.in/src-d/go-git.v4/plumbing/format/diff"
+	"gopkg.in/src-d/go-git.v4/plumbing/object"
+	"gopkg.in/src-d/go-git.v4/utils/diff"
+	"io"
+	"sort"
+	"strings"
+)
+
+// DiffCommand shows unified diff between revisions of a gist.
+// If the gist is cloned, the local repository is used. Otherwise revisions are retrieved via GitHub API.
+type DiffCommand struct {
+	ProfileName
+	// Target is id or name of a gist. Only id is available for a gist which is not cloned.
+	Target string
+	// From is the base revision. If empty, the previous revision of To is used.
+	From string
+	// To is the revision to compare. If empty, the latest revision is used.
+	To     string
+	Writer io.Writer
+}
+
+// Run command of DiffCommand
+func (dc *DiffCommand) Run(ctx context.Context, pctx ProfileContext) error {
+	if dc.Writer == nil {
+		return errors.New("DiffCommand_Run: writer is not given")
+	}
+	target, err := resolveGistTarget(pctx, dc.ProfileName, dc.Target)
+	if err != nil {
+		return fmt.Errorf("DiffCommand_Run_ResolveTarget: %w", err)
+	}
+	gistID := target.GistID
+	if target.Metadata != nil {
+		directory, err := target.Resolve(target.Metadata.RelativePath())
+		if err != nil {
+			return fmt.Errorf("DiffCommand_Run_ResolveRepository: %w", err)
+		}
+		repository, err := git.PlainOpen(directory)
+		if err == nil {
+			return dc.localDiff(repository)
+		}
+	}
+	return dc.remoteDiff(ctx, pctx, gistID)
+}
+
+func (dc *DiffCommand) localDiff(repository *git.Repository) error {
+	to := dc.To
+	if to == "" {
+		to = "HEAD"
+	}
+	toCommit, err := resolveCommit(repository, to)
+	if err != nil {
+		return fmt.Errorf("DiffCommand_LocalDiff_ResolveTo: %w", err)
+	}
+	toTree, err := toCommit.Tree()
+	if err != nil {
+		return fmt.Errorf("DiffCommand_LocalDiff_ToTree: %w", err)
+	}
+	var fromTree *object.Tree
+	if dc.From != "" {
+		fromCommit, err := resolveCommit(repository, dc.From)
+		if err != nil {
+			return fmt.Errorf("DiffCommand_LocalDiff_ResolveFrom: %w", err)
+		}
+		fromTree, err = fromCommit.Tree()
+		if err != nil {
+			return fmt.Errorf("DiffCommand_LocalDiff_FromTree: %w", err)
+		}
+	} else if toCommit.NumParents() > 0 {
+		parent, err := toCommit.Parent(0)
+		if err != nil {
+			return fmt.Errorf("DiffCommand_LocalDiff_Parent: %w", err)
+		}
+		fromTree, err = parent.Tree()
+		if err != nil {
+			return fmt.Errorf("DiffCommand_LocalDiff_FromTree: %w", err)
+		}
+	}
+	changes, err := object.DiffTree(fromTree, toTree)
+	if err != nil {
+		return fmt.Errorf("DiffCommand_LocalDiff_DiffTree: %w", err)
+	}
+	patch, err := changes.Patch()
+	if err != nil {
+		return fmt.Errorf("DiffCommand_LocalDiff_Patch: %w", err)
+	}
+	return patch.Encode(dc.Writer)
+}
+
+// resolveCommit finds a commit by revision, or by abbreviated hash reachable from HEAD.
+func resolveCommit(repository *git.Repository, revision string) (*object.Commit, error) {
+	hash, err := repository.ResolveRevision(plumbing.Revision(revision))
+	if err == nil {
+		commit, err := repository.CommitObject(*hash)
+		if err == nil {
+			return commit, nil
+		}
+	}
+	commits, err := repository.Log(&git.LogOptions{})
+	if err != nil {
+		return nil, err
+	}
+	defer commits.Close()
+	for {
+		commit, err := commits.Next()
+		if errors.Is(err, io.EOF) {
+			return nil, fmt.Errorf("no revision found: %s", revision)
+		}
+		if err != nil {
+			return nil, err
+		}
+		if strings.HasPrefix(commit.Hash.String(), revision) {
+			return commit, nil
+		}
+	}
+}
+
+func (dc *DiffCommand) remoteDiff(ctx context.Context, pctx ProfileContext, gistID GistID) error {
+	gitHub := pctx.NewGitHub()
+	gist, err := gitHub.GetGist(ctx, gistID, dc.ProfileName)
+	if err != nil {
+		return fmt.Errorf("DiffCommand_RemoteDiff_GetGist: %w", err)
+	}
+	if len(gist.History) == 0 {
+		return fmt.Errorf("no revision found in gist %s", gistID)
+	}
+	toIndex := 0
+	if dc.To != "" {
+		toIndex, err = findRevision(gist.History, dc.To)
+		if err != nil {
+			return err
+		}
+	}
+	fromIndex := toIndex + 1
+	if dc.From != "" {
+		fromIndex, err = findRevision(gist.History, dc.From)
+		if err != nil {
+			return err
+		}
+	}
+
+	toFiles := gist.Files
+	if toIndex != 0 {
+		toFiles, err = revisionFiles(ctx, gitHub, gistID, gist.History[toIndex].Version, dc.ProfileName)
+		if err != nil {
+			return err
+		}
+	}
+	fromFiles := map[string]GistFile{}
+	if fromIndex < len(gist.History) {
+		fromFiles, err = revisionFiles(ctx, gitHub, gistID, gist.History[fromIndex].Version, dc.ProfileName)
+		if err != nil {
+			return err
+		}
+	}
+	patch, err := newFilesPatch(fromFiles, toFiles)
+	if err != nil {
+		return fmt.Errorf("DiffCommand_RemoteDiff_Patch: %w", err)
+	}
+	return fdiff.NewUnifiedEncoder(dc.Writer, fdiff.DefaultContextLines).Encode(patch)
+}
+
+func revisionFiles(ctx context.Context, gitHub GitHub, gistID GistID, version string, profileName ProfileName) (map[string]GistFile, error) {
+	gist, err := gitHub.GetGistRevision(ctx, gistID, version, profileName)
+	if err != nil {
+		return nil, fmt.Errorf("DiffCommand_RemoteDiff_GetGistRevision: %w", err)
+	}
+	return gist.Files, nil
+}
+
+// findRevision returns index of the revision whose version starts with the given one.
+func findRevision(history []GistRevision, version string) (int, error) {
+	for i, revision := range history {
+		if strings.HasPrefix(revision.Version, version) {
+			return i, nil
+		}
+	}
+	return -1, fmt.Errorf("no revision found: %s", version)
+}
+
+// newFilesPatch creates patch between files of two revisions.
+func newFilesPatch(from, to map[string]GistFile) (fdiff.Patch, error) {
+	names := make([]string, 0, len(from)+len(to))
+	for name := range from {
+		names = append(names, name)
+	}
+	for name := range to {
+		if _, exists := from[name]; !exists {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+
+	patches := make(filesPatch, 0, len(names))
+	for _, name := range names {
+		fromFile, inFrom := from[name]
+		toFile, inTo := to[name]
+		if fromFile.Truncated || toFile.Truncated {
+			return nil, fmt.Errorf("file %s is too large to diff via API, clone the gist instead", name)
+		}
+		if inFrom && inTo && fromFile.Content == toFile.Content {
+			continue
+		}
+		patch := &filePatch{}
+		if inFrom {
+			patch.from = newPatchFile(name, fromFile.Content)
+		}
+		if inTo {
+			patch.to = newPatchFile(name, toFile.Content)
+		}
+		for _, d := range diff.Do(fromFile.Content, toFile.Content) {
+			patch.chunks = append(patch.chunks, &textChunk{content: d.Text, operation: operationOf(d.Type)})
+		}
+		patches = append(patches, patch)
+	}
+	return patches, nil
+}
+
+func operationOf(operation diffmatchpatch.Operation) fdiff.Operation {
+	switch operation {
+	case diffmatchpatch.DiffInsert:
+		return fdiff.Add
+	case diffmatchpatch.DiffDelete:
+		return fdiff.Delete
+	}
+	return fdiff.Equal
+}
+
+type filesPatch []fdiff.FilePatch
+
+func (p filesPatch) FilePatches() []fdiff.FilePatch {
+	return p
+}
+
+func (p filesPatch) Message() string {
+	return ""
+}
+
+type filePatch struct {
+	from   fdiff.File
+	to     fdiff.File
+	chunks []fdiff.Chunk
+}
+
+func (p *filePatch) IsBinary() bool {
+	return false
+}
+
+func (p *filePatch) Files() (fdiff.File, fdiff.File) {
+	return p.from, p.to
+}
+
+func (p *filePatch) Chunks() []fdiff.Chunk {
+	return p.chunks
+}
+
+type patchFile struct {
+	hash plumbing.Hash
+	path string
+}
+
+// newPatchFile creates a file of patch, whose hash is the same as git's blob hash.
+func newPatchFile(path string, content string) *patchFile {
+	return &patchFile{
+		hash: plumbing.ComputeHash(plumbing.BlobObject, []byte(content)),
+		path: path,
+	}
+}
+
+func (f *patchFile) Hash() plumbing.Hash {
+	return f.hash
+}
+
+func (f *patchFile) Mode() filemode.FileMode {
+	return filemode.Regular
+}
+
+func (f *patchFile) Path() string {
+	return f.path
+}
+
+type textChunk struct {
+	content   string
+	operation fdiff.Operation
+}
+
+func (c *textChunk) Content() string {
+	return c.content
+}
+
+func (c *textChunk) Type() fdiff.Operation {
+	return c.operation
+}
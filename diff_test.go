@@ -0,0 +1,100 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"github.com/stretchr/testify/assert"
+	"gopkg.in/src-d/go-git.v4"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestDiffCommand_Run_Remote(t *testing.T) {
+	ctx, stop := startTestGitHubServer(func(writer http.ResponseWriter, request *http.Request) {
+		switch request.URL.Path {
+		case "/gists/aa11":
+			_, _ = writer.Write([]byte(`{"id":"aa11","history":[{"version":"v3"},{"version":"v2"},{"version":"v1"}],
+"files":{"a.txt":{"filename":"a.txt","content":"hello\nworld\n"},"b.txt":{"filename":"b.txt","content":"new\n"}}}`))
+		case "/gists/aa11/v2":
+			_, _ = writer.Write([]byte(`{"id":"aa11","files":{"a.txt":{"filename":"a.txt","content":"hello\n"}}}`))
+		default:
+			assert.Fail(t, "unexpected request", request.URL.Path)
+			writer.WriteHeader(http.StatusNotFound)
+		}
+	})
+	defer stop()
+	ctx.CurrentProfiles[0].Dir = "build/test/not-existing"
+
+	buffer := new(bytes.Buffer)
+	command := DiffCommand{ProfileName: "default", Target: "aa11", Writer: buffer}
+	err := command.Run(context.Background(), ctx)
+	assert.Nil(t, err)
+	output := buffer.String()
+	assert.True(t, strings.HasPrefix(output, "diff --git a/a.txt b/a.txt\n"), output)
+	assert.Contains(t, output, "--- a/a.txt\n+++ b/a.txt\n@@ -1 +1,2 @@\n hello\n+world\n")
+	assert.Contains(t, output, "diff --git a/b.txt b/b.txt\nnew file mode 100644\n")
+	assert.Contains(t, output, "+++ b/b.txt\n@@ -0,0 +1 @@\n+new\n")
+}
+
+func TestDiffCommand_Run_RemoteUnknownRevision(t *testing.T) {
+	ctx, stop := startTestGitHubServer(func(writer http.ResponseWriter, request *http.Request) {
+		_, _ = writer.Write([]byte(`{"id":"aa11","history":[{"version":"v1"}]}`))
+	})
+	defer stop()
+	ctx.CurrentProfiles[0].Dir = "build/test/not-existing"
+
+	command := DiffCommand{ProfileName: "default", Target: "aa11", From: "x9", Writer: new(bytes.Buffer)}
+	err := command.Run(context.Background(), ctx)
+	assert.NotNil(t, err)
+}
+
+func TestDiffCommand_Run_Local(t *testing.T) {
+	parent, err := ioutil.TempDir("", "diff-test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() { _ = os.RemoveAll(parent) }()
+	_, clone := prepareOriginAndClone(t, parent)
+	repository, err := git.PlainOpen(clone)
+	if err != nil {
+		t.Fatal(err)
+	}
+	commitFile(t, repository, clone, "test.md", "# test\nupdated\n")
+	md := RepositoryMetadata{ID: "aa11", Name: "clone"}
+	saveTestMetadata(t, filepath.Join(parent, ".gist"), md)
+	ctx := ProfileContext{CurrentProfiles: []Profile{{Name: "default", Dir: DestinationDir(parent)}}}
+
+	buffer := new(bytes.Buffer)
+	command := DiffCommand{ProfileName: "default", Target: "clone", Writer: buffer}
+	err = command.Run(context.Background(), ctx)
+	assert.Nil(t, err)
+	assert.Contains(t, buffer.String(), "@@ -1 +1,2 @@\n # test\n+updated\n")
+
+	head, err := repository.Head()
+	if err != nil {
+		t.Fatal(err)
+	}
+	buffer.Reset()
+	command = DiffCommand{ProfileName: "default", Target: "aa11", From: head.Hash().String()[:7], To: "HEAD~1", Writer: buffer}
+	err = command.Run(context.Background(), ctx)
+	assert.Nil(t, err)
+	assert.Contains(t, buffer.String(), "@@ -1,2 +1 @@\n # test\n-updated\n")
+}
+
+func TestFindRevision(t *testing.T) {
+	history := []GistRevision{{Version: "abc123"}, {Version: "def456"}}
+	index, err := findRevision(history, "def")
+	assert.Nil(t, err)
+	assert.Equal(t, 1, index)
+	_, err = findRevision(history, "999")
+	assert.NotNil(t, err)
+}
+
+func TestNewFilesPatch_Truncated(t *testing.T) {
+	_, err := newFilesPatch(map[string]GistFile{}, map[string]GistFile{"a.txt": {Truncated: true}})
+	assert.NotNil(t, err)
+}
@@ -0,0 +1,127 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"path/filepath"
+	"strings"
+)
+
+// EditCommand changes description and files of a gist through GitHub API.
+type EditCommand struct {
+	ProfileName
+	// Target is id or name of a gist. Only id is available for a gist which is not cloned.
+	Target string
+	// Description is new description. If nil, description is not changed.
+	Description *string
+	// Files are files to upload, each of them is `path` or `name=path`.
+	Files []string
+	// Renames are files to rename, each of them is `old=new`.
+	Renames []string
+	// Deletes are names of files to delete.
+	Deletes []string
+	Writer  io.Writer
+}
+
+// Run command of EditCommand
+func (ec *EditCommand) Run(ctx context.Context, pctx ProfileContext) error {
+	if ec.Writer == nil {
+		return errors.New("EditCommand_Run: writer is not given")
+	}
+	update, err := ec.GistUpdate()
+	if err != nil {
+		return fmt.Errorf("EditCommand_Run_GistUpdate: %w", err)
+	}
+	target, err := resolveGistTarget(pctx, ec.ProfileName, ec.Target)
+	if err != nil {
+		return fmt.Errorf("EditCommand_Run_ResolveTarget: %w", err)
+	}
+	gistID := target.GistID
+
+	gitHub := pctx.NewGitHub()
+	gist, err := gitHub.UpdateGist(ctx, gistID, *update, ec.ProfileName)
+	if err != nil {
+		return fmt.Errorf("EditCommand_Run_UpdateGist: %w", err)
+	}
+	_, _ = fmt.Fprintf(ec.Writer, "updated %s\n", gistID)
+	if target.Metadata == nil {
+		return nil
+	}
+	metadata, err := NewMetadataFromGist(RepositoryName(target.Metadata.Name), *gist)
+	if err != nil {
+		return fmt.Errorf("EditCommand_Run_CreateMetadata: %w", err)
+	}
+	metadata.inheritFrom(*target.Metadata)
+	target.MetadataIndex.Upsert(*metadata)
+	err = target.MetadataIndex.Save()
+	if err != nil {
+		return fmt.Errorf("EditCommand_Run_SaveMetadata: %w", err)
+	}
+	if len(update.Files) > 0 {
+		_, _ = fmt.Fprintf(ec.Writer, "files of the local clone are not changed, run `gist pull %s` to update them\n", ec.Target)
+	}
+	return nil
+}
+
+// GistUpdate converts options into GistUpdate.
+func (ec *EditCommand) GistUpdate() (*GistUpdate, error) {
+	files := make(map[string]*GistFileUpdate)
+	for _, file := range ec.Files {
+		name, path := splitPair(file)
+		if path == "" {
+			name, path = filepath.Base(file), file
+		}
+		contents, err := ioutil.ReadFile(path)
+		if err != nil {
+			return nil, fmt.Errorf("ReadFile(%s): %w", path, err)
+		}
+		if len(contents) == 0 {
+			return nil, fmt.Errorf("file %s is empty, use delete to remove it", path)
+		}
+		fileUpdate := fileUpdateOf(files, name)
+		fileUpdate.Content = string(contents)
+	}
+	for _, rename := range ec.Renames {
+		oldName, newName := splitPair(rename)
+		if oldName == "" || newName == "" {
+			return nil, fmt.Errorf("invalid rename format: %s(expected: old=new)", rename)
+		}
+		fileUpdate := fileUpdateOf(files, oldName)
+		fileUpdate.Filename = newName
+	}
+	for _, name := range ec.Deletes {
+		if _, exists := files[name]; exists {
+			return nil, fmt.Errorf("file %s cannot be deleted and changed at the same time", name)
+		}
+		files[name] = nil
+	}
+	if ec.Description == nil && len(files) == 0 {
+		return nil, errors.New("nothing to edit")
+	}
+	update := GistUpdate{Description: ec.Description}
+	if len(files) > 0 {
+		update.Files = files
+	}
+	return &update, nil
+}
+
+func fileUpdateOf(files map[string]*GistFileUpdate, name string) *GistFileUpdate {
+	fileUpdate, exists := files[name]
+	if !exists {
+		fileUpdate = &GistFileUpdate{}
+		files[name] = fileUpdate
+	}
+	return fileUpdate
+}
+
+// splitPair splits `key=value` into key and value. If there is no `=`, value will be empty.
+func splitPair(pair string) (string, string) {
+	index := strings.Index(pair, "=")
+	if index < 0 {
+		return pair, ""
+	}
+	return pair[:index], pair[index+1:]
+}
@@ -0,0 +1,116 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"github.com/stretchr/testify/assert"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestEditCommand_GistUpdate(t *testing.T) {
+	description := "new description"
+	command := EditCommand{
+		Description: &description,
+		Files:       []string{"testdata/profile.yml", "renamed.yml=testdata/profile.yml"},
+		Renames:     []string{"renamed.yml=config.yml", "main.go=app.go"},
+		Deletes:     []string{"old.md"},
+	}
+	update, err := command.GistUpdate()
+	assert.Nil(t, err)
+	assert.Equal(t, &description, update.Description)
+	assert.Equal(t, 4, len(update.Files))
+	assert.NotEmpty(t, update.Files["profile.yml"].Content)
+	assert.Equal(t, "", update.Files["profile.yml"].Filename)
+	assert.NotEmpty(t, update.Files["renamed.yml"].Content)
+	assert.Equal(t, "config.yml", update.Files["renamed.yml"].Filename)
+	assert.Equal(t, &GistFileUpdate{Filename: "app.go"}, update.Files["main.go"])
+	deleted, exists := update.Files["old.md"]
+	assert.True(t, exists)
+	assert.Nil(t, deleted)
+}
+
+func TestEditCommand_GistUpdate_JSON(t *testing.T) {
+	command := EditCommand{
+		Renames: []string{"main.go=app.go"},
+		Deletes: []string{"old.md"},
+	}
+	update, err := command.GistUpdate()
+	assert.Nil(t, err)
+	bytes, err := json.Marshal(update)
+	assert.Nil(t, err)
+	assert.JSONEq(t, `{"files":{"main.go":{"filename":"app.go"},"old.md":null}}`, string(bytes))
+}
+
+func TestEditCommand_GistUpdate_Nothing(t *testing.T) {
+	command := EditCommand{}
+	_, err := command.GistUpdate()
+	assert.NotNil(t, err)
+}
+
+func TestEditCommand_GistUpdate_DeleteAndRename(t *testing.T) {
+	command := EditCommand{
+		Renames: []string{"main.go=app.go"},
+		Deletes: []string{"main.go"},
+	}
+	_, err := command.GistUpdate()
+	assert.NotNil(t, err)
+}
+
+func TestEditCommand_GistUpdate_InvalidRename(t *testing.T) {
+	command := EditCommand{
+		Renames: []string{"main.go"},
+	}
+	_, err := command.GistUpdate()
+	assert.NotNil(t, err)
+}
+
+func TestEditCommand_Run_UpdatesMetadata(t *testing.T) {
+	parent, err := ioutil.TempDir("", "edit-test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() { _ = os.RemoveAll(parent) }()
+	md := RepositoryMetadata{ID: "aa11", Name: "first", Description: "old description"}
+	saveTestMetadata(t, filepath.Join(parent, ".gist"), md)
+	ctx, stop := startTestGitHubServer(func(writer http.ResponseWriter, request *http.Request) {
+		assert.Equal(t, "PATCH", request.Method)
+		assert.Equal(t, "/gists/aa11", request.URL.Path)
+		bytes, _ := ioutil.ReadAll(request.Body)
+		assert.JSONEq(t, `{"description":"new description"}`, string(bytes))
+		_, _ = writer.Write([]byte(`{"id":"aa11","description":"new description","created_at":"2020-01-01T00:00:00Z","owner":{"login":"test-user"}}`))
+	})
+	defer stop()
+	ctx.CurrentProfiles[0].Dir = DestinationDir(parent)
+
+	description := "new description"
+	buffer := new(bytes.Buffer)
+	command := EditCommand{ProfileName: "default", Target: "first", Description: &description, Writer: buffer}
+	err = command.Run(context.Background(), ctx)
+	assert.Nil(t, err)
+	assert.Equal(t, "updated aa11\n", buffer.String())
+	items, err := LoadMetadataFrom(filepath.Join(parent, ".gist"))
+	assert.Nil(t, err)
+	secret := false
+	assert.Equal(t, []RepositoryMetadata{{
+		ID:          "aa11",
+		Name:        "first",
+		Description: "new description",
+		Owner:       "test-user",
+		Created:     1577836800,
+		Public:      &secret,
+	}}, items)
+}
+
+func TestSplitPair(t *testing.T) {
+	key, value := splitPair("main.go=app.go")
+	assert.Equal(t, "main.go", key)
+	assert.Equal(t, "app.go", value)
+	key, value = splitPair("main.go")
+	assert.Equal(t, "main.go", key)
+	assert.Equal(t, "", value)
+}
@@ -0,0 +1,87 @@
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// lockTimeout is how long to wait for a lock held by another process.
+var lockTimeout = 10 * time.Second
+
+// lockRetryInterval is the interval of attempts to obtain a lock.
+var lockRetryInterval = 50 * time.Millisecond
+
+// LockTimeoutError is returned when a lock of file cannot be obtained within timeout.
+type LockTimeoutError struct {
+	Path    string
+	Timeout time.Duration
+}
+
+func (e *LockTimeoutError) Error() string {
+	return fmt.Sprintf("%s is locked by another gist process(waited %s). If no gist process is running, remove %s", e.Path, e.Timeout, lockFileName(e.Path))
+}
+
+// lockFileName is the name of lock file of the file.
+func lockFileName(path string) string {
+	return path + ".lock"
+}
+
+// lockFile obtains an advisory lock of the file, which is held on a lock file next to it.
+// The lock is only respected by gist processes. The file itself can be replaced while the lock is held.
+func lockFile(path string) (*fileLock, error) {
+	deadline := time.Now().Add(lockTimeout)
+	for {
+		lock, err := tryLock(lockFileName(path))
+		if err != nil {
+			return nil, fmt.Errorf("LockFile(%s): %w", path, err)
+		}
+		if lock != nil {
+			return lock, nil
+		}
+		if time.Now().After(deadline) {
+			return nil, &LockTimeoutError{Path: path, Timeout: lockTimeout}
+		}
+		time.Sleep(lockRetryInterval)
+	}
+}
+
+// writeFileAtomically writes contents into a temporary file in the same directory, and renames it to path.
+// Permission of the existing file is kept. The caller should hold lock of the file.
+func writeFileAtomically(path string, write func(writer io.Writer) error) error {
+	mode := os.FileMode(0644)
+	if info, err := os.Stat(path); err == nil {
+		mode = info.Mode().Perm()
+	}
+	file, err := ioutil.TempFile(filepath.Dir(path), "."+filepath.Base(path)+".tmp-*")
+	if err != nil {
+		return err
+	}
+	temporary := file.Name()
+	defer func() { _ = os.Remove(temporary) }()
+
+	writer := bufio.NewWriter(file)
+	err = write(writer)
+	if err == nil {
+		err = writer.Flush()
+	}
+	if err == nil {
+		err = file.Sync()
+	}
+	closeErr := file.Close()
+	if err != nil {
+		return err
+	}
+	if closeErr != nil {
+		return closeErr
+	}
+	err = os.Chmod(temporary, mode)
+	if err != nil {
+		return err
+	}
+	return os.Rename(temporary, path)
+}
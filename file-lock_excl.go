@@ -0,0 +1,40 @@
+//go:build !(darwin || dragonfly || freebsd || linux || netbsd || openbsd)
+// +build !darwin,!dragonfly,!freebsd,!linux,!netbsd,!openbsd
+
+package main
+
+import (
+	"fmt"
+	"os"
+)
+
+// fileLock is a lock file created exclusively. The lock file is left if the process exits without Unlock.
+type fileLock struct {
+	path string
+}
+
+// tryLock creates the lock file exclusively. If it exists, nil will be returned.
+func tryLock(lockPath string) (*fileLock, error) {
+	file, err := os.OpenFile(lockPath, os.O_CREATE|os.O_EXCL|os.O_WRONLY, 0644)
+	if os.IsExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	_, err = fmt.Fprintf(file, "%d\n", os.Getpid())
+	closeErr := file.Close()
+	if err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		_ = os.Remove(lockPath)
+		return nil, err
+	}
+	return &fileLock{path: lockPath}, nil
+}
+
+// Unlock removes the lock file.
+func (lock *fileLock) Unlock() error {
+	return os.Remove(lock.path)
+}
@@ -0,0 +1,53 @@
+//go:build darwin || dragonfly || freebsd || linux || netbsd || openbsd
+// +build darwin dragonfly freebsd linux netbsd openbsd
+
+package main
+
+import (
+	"os"
+	"syscall"
+)
+
+// fileLock is a lock file locked with flock. The lock is released when the process exits.
+type fileLock struct {
+	file *os.File
+}
+
+// tryLock locks the lock file without blocking. If it is locked by another process, nil will be returned.
+func tryLock(lockPath string) (*fileLock, error) {
+	file, err := os.OpenFile(lockPath, os.O_CREATE|os.O_RDWR, 0644)
+	if err != nil {
+		return nil, err
+	}
+	err = syscall.Flock(int(file.Fd()), syscall.LOCK_EX|syscall.LOCK_NB)
+	if err == syscall.EWOULDBLOCK {
+		_ = file.Close()
+		return nil, nil
+	}
+	if err != nil {
+		_ = file.Close()
+		return nil, err
+	}
+	// the lock file may be removed by the previous holder before we lock it
+	locked, err := file.Stat()
+	if err != nil {
+		_ = file.Close()
+		return nil, err
+	}
+	current, err := os.Stat(lockPath)
+	if err != nil || !os.SameFile(locked, current) {
+		_ = file.Close()
+		return nil, nil
+	}
+	return &fileLock{file: file}, nil
+}
+
+// Unlock removes the lock file and releases the lock.
+func (lock *fileLock) Unlock() error {
+	err := os.Remove(lock.file.Name())
+	closeErr := lock.file.Close()
+	if err != nil {
+		return err
+	}
+	return closeErr
+}
@@ -0,0 +1,167 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"github.com/stretchr/testify/assert"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func prepareFileLockTestDir(t *testing.T) string {
+	dir, err := ioutil.TempDir("", "gist-lock")
+	if err != nil {
+		assert.Fail(t, "unexpected error@TempDir", err)
+	}
+	return dir
+}
+
+func shortLockTimeout() func() {
+	previous := lockTimeout
+	lockTimeout = 100 * time.Millisecond
+	return func() { lockTimeout = previous }
+}
+
+func TestLockFile_Timeout(t *testing.T) {
+	defer shortLockTimeout()()
+	dir := prepareFileLockTestDir(t)
+	defer func() { _ = os.RemoveAll(dir) }()
+	file := filepath.Join(dir, ".gist")
+
+	lock, err := lockFile(file)
+	assert.Nil(t, err)
+	_, err = lockFile(file)
+	var timeoutError *LockTimeoutError
+	assert.True(t, errors.As(err, &timeoutError), "%v", err)
+	assert.Contains(t, err.Error(), lockFileName(file))
+
+	assert.Nil(t, lock.Unlock())
+	_, err = os.Stat(lockFileName(file))
+	assert.True(t, os.IsNotExist(err))
+	lock, err = lockFile(file)
+	assert.Nil(t, err)
+	assert.Nil(t, lock.Unlock())
+}
+
+func TestWriteFileAtomically_KeepsPermission(t *testing.T) {
+	dir := prepareFileLockTestDir(t)
+	defer func() { _ = os.RemoveAll(dir) }()
+	file := filepath.Join(dir, ".gist.yml")
+	assert.Nil(t, ioutil.WriteFile(file, []byte("old"), 0600))
+
+	err := writeFileAtomically(file, func(writer io.Writer) error {
+		_, err := writer.Write([]byte("new"))
+		return err
+	})
+	assert.Nil(t, err)
+	contents, err := ioutil.ReadFile(file)
+	assert.Nil(t, err)
+	assert.Equal(t, "new", string(contents))
+	info, err := os.Stat(file)
+	assert.Nil(t, err)
+	assert.Equal(t, os.FileMode(0600), info.Mode().Perm())
+
+	err = writeFileAtomically(file, func(writer io.Writer) error {
+		return errors.New("failure")
+	})
+	assert.NotNil(t, err)
+	contents, err = ioutil.ReadFile(file)
+	assert.Nil(t, err)
+	assert.Equal(t, "new", string(contents))
+	entries, err := ioutil.ReadDir(dir)
+	assert.Nil(t, err)
+	assert.Equal(t, 1, len(entries))
+}
+
+func TestMetadataIndex_Save_KeepsChangesOfOthers(t *testing.T) {
+	dir := prepareFileLockTestDir(t)
+	defer func() { _ = os.RemoveAll(dir) }()
+	file := filepath.Join(dir, ".gist")
+	fixture, err := LoadMetadataIndex(file)
+	assert.Nil(t, err)
+	fixture.Upsert(RepositoryMetadata{ID: "aa11"})
+	fixture.Upsert(RepositoryMetadata{ID: "bb22"})
+	assert.Nil(t, fixture.Save())
+
+	first, err := LoadMetadataIndex(file)
+	assert.Nil(t, err)
+	second, err := LoadMetadataIndex(file)
+	assert.Nil(t, err)
+	first.Upsert(RepositoryMetadata{ID: "cc33"})
+	second.Upsert(RepositoryMetadata{ID: "aa11", Starred: true})
+	second.Delete("bb22")
+	assert.Nil(t, first.Save())
+	assert.Nil(t, second.Save())
+
+	items, err := LoadMetadataFrom(file)
+	assert.Nil(t, err)
+	assert.Equal(t, []RepositoryMetadata{{ID: "aa11", Starred: true}, {ID: "cc33"}}, items)
+	assert.Equal(t, items, second.Items())
+}
+
+func TestMetadataIndex_Save_Locked(t *testing.T) {
+	defer shortLockTimeout()()
+	dir := prepareFileLockTestDir(t)
+	defer func() { _ = os.RemoveAll(dir) }()
+	file := filepath.Join(dir, ".gist")
+
+	lock, err := lockFile(file)
+	assert.Nil(t, err)
+	defer func() { _ = lock.Unlock() }()
+	index, err := LoadMetadataIndex(file)
+	assert.Nil(t, err)
+	index.Upsert(RepositoryMetadata{ID: "aa11"})
+	err = index.Save()
+	var timeoutError *LockTimeoutError
+	assert.True(t, errors.As(err, &timeoutError), "%v", err)
+	_, err = os.Stat(file)
+	assert.True(t, os.IsNotExist(err))
+}
+
+func TestProfileFile_NewWriter_Locked(t *testing.T) {
+	defer shortLockTimeout()()
+	dir := prepareFileLockTestDir(t)
+	defer func() { _ = os.RemoveAll(dir) }()
+	profileFile := ProfileFile(filepath.Join(dir, ".gist.yml"))
+
+	writer, err := profileFile.NewWriter()
+	assert.Nil(t, err)
+	_, err = profileFile.NewWriter()
+	var timeoutError *LockTimeoutError
+	assert.True(t, errors.As(err, &timeoutError), "%v", err)
+	_, err = writer.Write([]byte("- profile: default\n"))
+	assert.Nil(t, err)
+	assert.Nil(t, writer.Close())
+
+	profiles, err := profileFile.LoadProfiles()
+	assert.Nil(t, err)
+	assert.Equal(t, 1, len(profiles))
+	_, err = os.Stat(lockFileName(string(profileFile)))
+	assert.True(t, os.IsNotExist(err))
+}
+
+func TestAppendOrOverrideProfilesCommand_Run_KeepsProfilesOfOthers(t *testing.T) {
+	dir := prepareFileLockTestDir(t)
+	defer func() { _ = os.RemoveAll(dir) }()
+	profileFile := ProfileFile(filepath.Join(dir, ".gist.yml"))
+	pctx := ProfileContext{ProfileFile: profileFile, CurrentProfiles: []Profile{}}
+	// another process adds a profile after this process loaded profiles
+	assert.Nil(t, ioutil.WriteFile(string(profileFile), []byte("- profile: other\n"), 0600))
+
+	command := AppendOrOverrideProfilesCommand{ProfileName: "default", GitHubAccessToken: "aa00bb11cc22"}
+	err := command.Run(context.Background(), pctx)
+	assert.Nil(t, err)
+
+	profiles, err := profileFile.LoadProfiles()
+	assert.Nil(t, err)
+	assert.Equal(t, 2, len(profiles))
+	assert.Equal(t, ProfileName("default"), profiles[0].Name)
+	assert.Equal(t, ProfileName("other"), profiles[1].Name)
+	info, err := os.Stat(string(profileFile))
+	assert.Nil(t, err)
+	assert.Equal(t, os.FileMode(0600), info.Mode().Perm())
+}
@@ -0,0 +1,45 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+)
+
+// ForkCommand forks a gist under the profile's account, then clones the fork.
+type ForkCommand struct {
+	GistID
+	ProfileName
+	PreferSSH
+	RepositoryName
+	Writer io.Writer
+}
+
+// Run command of ForkCommand
+func (fc *ForkCommand) Run(ctx context.Context, pctx ProfileContext) error {
+	if fc.Writer == nil {
+		return errors.New("ForkCommand_Run: writer is not given")
+	}
+	gitHub := pctx.NewGitHub()
+	fork, err := gitHub.ForkGist(ctx, fc.GistID, fc.ProfileName)
+	if err != nil {
+		return fmt.Errorf("ForkCommand_Run_ForkGist: %w", err)
+	}
+	_, _ = fmt.Fprintf(fc.Writer, "forked %s into %s\n", fc.GistID, fork.ID)
+	command := CloneCommand{
+		GistID:         GistID(fork.ID),
+		ProfileName:    fc.ProfileName,
+		PreferSSH:      fc.PreferSSH,
+		RepositoryName: fc.RepositoryName,
+	}
+	cloned, err := command.CloneRepository(ctx, pctx)
+	if err != nil {
+		return fmt.Errorf("ForkCommand_Run_Clone(%s): %w", fork.ID, err)
+	}
+	if cloned.Metadata.ForkOf == "" {
+		cloned.Metadata.ForkOf = string(fc.GistID)
+	}
+	_, _ = fmt.Fprintln(fc.Writer, cloned.Directory)
+	return cloned.Record()
+}
@@ -0,0 +1,53 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"github.com/stretchr/testify/assert"
+	"net/http"
+	"testing"
+)
+
+func TestGitHubImpl_ForkGist(t *testing.T) {
+	ctx, stop := startTestGitHubServer(func(writer http.ResponseWriter, request *http.Request) {
+		assert.Equal(t, "POST", request.Method)
+		assert.Equal(t, "/gists/aa11/forks", request.URL.Path)
+		writer.WriteHeader(http.StatusCreated)
+		_, _ = writer.Write([]byte(`{"id":"bb22","owner":{"login":"test-user"},"fork_of":{"id":"aa11"}}`))
+	})
+	defer stop()
+
+	gist, err := ctx.NewGitHub().ForkGist(context.Background(), "aa11", "default")
+	assert.Nil(t, err)
+	assert.Equal(t, "bb22", gist.ID)
+	assert.Equal(t, "aa11", gist.ForkOf.ID)
+}
+
+func TestForkCommand_Run_ForkError(t *testing.T) {
+	ctx, stop := startTestGitHubServer(func(writer http.ResponseWriter, request *http.Request) {
+		writer.WriteHeader(http.StatusNotFound)
+	})
+	defer stop()
+
+	buffer := new(bytes.Buffer)
+	command := ForkCommand{GistID: "aa11", ProfileName: "default", Writer: buffer}
+	err := command.Run(context.Background(), ctx)
+	assert.NotNil(t, err)
+	assert.Equal(t, "", buffer.String())
+}
+
+func TestNewMetadataFromGist_ForkOf(t *testing.T) {
+	metadata, err := NewMetadataFromGist("", Gist{
+		ID:        "bb22",
+		CreatedAt: "2020-01-01T00:00:00Z",
+		ForkOf:    &ParentGist{ID: "aa11"},
+	})
+	assert.Nil(t, err)
+	assert.Equal(t, "aa11", metadata.ForkOf)
+}
+
+func TestRepositoryMetadata_InheritFrom(t *testing.T) {
+	metadata := RepositoryMetadata{ID: "bb22"}
+	metadata.inheritFrom(RepositoryMetadata{ID: "bb22", Starred: true, ForkOf: "aa11"})
+	assert.Equal(t, RepositoryMetadata{ID: "bb22", Starred: true, ForkOf: "aa11"}, metadata)
+}
@@ -0,0 +1,129 @@
+package main
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"gopkg.in/yaml.v2"
+	"io"
+	"reflect"
+)
+
+// OutputFormat is a name of output format.
+type OutputFormat string
+
+// Records is a set of items to be shown by Formatter.
+type Records interface {
+	// Items returns a slice of items for structured formats(json, xml, yaml).
+	Items() interface{}
+	// XMLNames returns names of root element and each item element.
+	XMLNames() (root string, item string)
+	// Header returns column names for tabular formats(csv, tsv).
+	Header() []string
+	// Rows returns values for tabular formats(csv, tsv).
+	Rows() [][]string
+}
+
+// Formatter writes Records in a format.
+type Formatter interface {
+	Format(writer io.Writer, records Records) error
+}
+
+var formatters = map[OutputFormat]Formatter{
+	"json": &jsonFormatter{},
+	"xml":  &xmlFormatter{},
+	"yaml": &yamlFormatter{},
+	"csv":  &separatedValuesFormatter{separator: ','},
+	"tsv":  &separatedValuesFormatter{separator: '\t'},
+}
+
+// NewFormatter returns Formatter for the OutputFormat.
+func NewFormatter(format OutputFormat) (Formatter, error) {
+	formatter, ok := formatters[format]
+	if !ok {
+		return nil, fmt.Errorf("unknown output format: %s(available: json, xml, yaml, csv, tsv)", format)
+	}
+	return formatter, nil
+}
+
+type jsonFormatter struct{}
+
+func (*jsonFormatter) Format(writer io.Writer, records Records) error {
+	encoder := json.NewEncoder(writer)
+	encoder.SetIndent("", "  ")
+	err := encoder.Encode(records.Items())
+	if err != nil {
+		return fmt.Errorf("jsonFormatter_Format_Encode: %w", err)
+	}
+	return nil
+}
+
+type xmlFormatter struct{}
+
+func (*xmlFormatter) Format(writer io.Writer, records Records) error {
+	root, item := records.XMLNames()
+	_, err := io.WriteString(writer, xml.Header)
+	if err != nil {
+		return fmt.Errorf("xmlFormatter_Format_WriteHeader: %w", err)
+	}
+	encoder := xml.NewEncoder(writer)
+	encoder.Indent("", "  ")
+	start := xml.StartElement{Name: xml.Name{Local: root}}
+	err = encoder.EncodeToken(start)
+	if err != nil {
+		return fmt.Errorf("xmlFormatter_Format_EncodeRoot(%s): %w", root, err)
+	}
+	items := reflect.ValueOf(records.Items())
+	for i := 0; i < items.Len(); i++ {
+		err = encoder.EncodeElement(items.Index(i).Interface(), xml.StartElement{Name: xml.Name{Local: item}})
+		if err != nil {
+			return fmt.Errorf("xmlFormatter_Format_EncodeItem(%s): %w", item, err)
+		}
+	}
+	err = encoder.EncodeToken(start.End())
+	if err != nil {
+		return fmt.Errorf("xmlFormatter_Format_EncodeRootEnd(%s): %w", root, err)
+	}
+	err = encoder.Flush()
+	if err != nil {
+		return fmt.Errorf("xmlFormatter_Format_Flush: %w", err)
+	}
+	_, err = io.WriteString(writer, "\n")
+	if err != nil {
+		return fmt.Errorf("xmlFormatter_Format_WriteNewLine: %w", err)
+	}
+	return nil
+}
+
+type yamlFormatter struct{}
+
+func (*yamlFormatter) Format(writer io.Writer, records Records) error {
+	bytes, err := yaml.Marshal(records.Items())
+	if err != nil {
+		return fmt.Errorf("yamlFormatter_Format_Marshal: %w", err)
+	}
+	_, err = writer.Write(bytes)
+	if err != nil {
+		return fmt.Errorf("yamlFormatter_Format_Write: %w", err)
+	}
+	return nil
+}
+
+type separatedValuesFormatter struct {
+	separator rune
+}
+
+func (f *separatedValuesFormatter) Format(writer io.Writer, records Records) error {
+	csvWriter := csv.NewWriter(writer)
+	csvWriter.Comma = f.separator
+	err := csvWriter.Write(records.Header())
+	if err != nil {
+		return fmt.Errorf("separatedValuesFormatter_Format_WriteHeader: %w", err)
+	}
+	err = csvWriter.WriteAll(records.Rows())
+	if err != nil {
+		return fmt.Errorf("separatedValuesFormatter_Format_WriteRows: %w", err)
+	}
+	return nil
+}
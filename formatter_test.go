@@ -0,0 +1,126 @@
+package main
+
+import (
+	"bytes"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+var formatterTestRecords = metadataRecords{
+	{
+		ID:      "1a2bc3d4ef",
+		Name:    "test",
+		URL:     "https://api.github.com/gists/1a2bc3d4ef",
+		GitURL:  "https://gist.github.com/1a2bc3d4ef.git",
+		Owner:   "test-user",
+		Created: 1577836800,
+	},
+	{
+		ID:          "1100aaccb2",
+		Description: "a gist, for test",
+		URL:         "https://api.github.com/gists/1100aaccb2",
+		GitURL:      "https://gist.github.com/1100aaccb2.git",
+		Owner:       "new-user",
+		Created:     1580515200,
+	},
+}
+
+func TestNewFormatter_UnknownFormat(t *testing.T) {
+	formatter, err := NewFormatter("html")
+	assert.NotNil(t, err)
+	assert.Nil(t, formatter)
+}
+
+func TestJsonFormatter_Format(t *testing.T) {
+	formatter, err := NewFormatter("json")
+	assert.Nil(t, err)
+	buffer := new(bytes.Buffer)
+	err = formatter.Format(buffer, formatterTestRecords)
+	assert.Nil(t, err)
+	assert.Equal(t, `[
+  {
+    "id": "1a2bc3d4ef",
+    "name": "test",
+    "url": "https://api.github.com/gists/1a2bc3d4ef",
+    "git_url": "https://gist.github.com/1a2bc3d4ef.git",
+    "owner": "test-user",
+    "created": 1577836800
+  },
+  {
+    "id": "1100aaccb2",
+    "description": "a gist, for test",
+    "url": "https://api.github.com/gists/1100aaccb2",
+    "git_url": "https://gist.github.com/1100aaccb2.git",
+    "owner": "new-user",
+    "created": 1580515200
+  }
+]
+`, buffer.String())
+}
+
+func TestXmlFormatter_Format(t *testing.T) {
+	formatter, err := NewFormatter("xml")
+	assert.Nil(t, err)
+	buffer := new(bytes.Buffer)
+	err = formatter.Format(buffer, formatterTestRecords[:1])
+	assert.Nil(t, err)
+	assert.Equal(t, `<?xml version="1.0" encoding="UTF-8"?>
+<gists>
+  <gist>
+    <id>1a2bc3d4ef</id>
+    <name>test</name>
+    <url>https://api.github.com/gists/1a2bc3d4ef</url>
+    <git_url>https://gist.github.com/1a2bc3d4ef.git</git_url>
+    <owner>test-user</owner>
+    <created>1577836800</created>
+  </gist>
+</gists>
+`, buffer.String())
+}
+
+func TestYamlFormatter_Format(t *testing.T) {
+	formatter, err := NewFormatter("yaml")
+	assert.Nil(t, err)
+	buffer := new(bytes.Buffer)
+	err = formatter.Format(buffer, formatterTestRecords[1:])
+	assert.Nil(t, err)
+	assert.Equal(t, `- id: 1100aaccb2
+  description: a gist, for test
+  url: https://api.github.com/gists/1100aaccb2
+  git_url: https://gist.github.com/1100aaccb2.git
+  owner: new-user
+  created: 1580515200
+`, buffer.String())
+}
+
+func TestCsvFormatter_Format(t *testing.T) {
+	formatter, err := NewFormatter("csv")
+	assert.Nil(t, err)
+	buffer := new(bytes.Buffer)
+	err = formatter.Format(buffer, formatterTestRecords)
+	assert.Nil(t, err)
+	assert.Equal(t, `id,name,description,url,git_url,owner,created,starred,fork_of,html_url,public,updated,comments,forks,files,languages,path
+1a2bc3d4ef,test,,https://api.github.com/gists/1a2bc3d4ef,https://gist.github.com/1a2bc3d4ef.git,test-user,1577836800,false,,,,0,0,0,,,test
+1100aaccb2,,"a gist, for test",https://api.github.com/gists/1100aaccb2,https://gist.github.com/1100aaccb2.git,new-user,1580515200,false,,,,0,0,0,,,1100aaccb2
+`, buffer.String())
+}
+
+func TestTsvFormatter_Format(t *testing.T) {
+	formatter, err := NewFormatter("tsv")
+	assert.Nil(t, err)
+	buffer := new(bytes.Buffer)
+	err = formatter.Format(buffer, formatterTestRecords[:1])
+	assert.Nil(t, err)
+	assert.Equal(t, "id\tname\tdescription\turl\tgit_url\towner\tcreated\tstarred\tfork_of\thtml_url\tpublic\tupdated\tcomments\tforks\tfiles\tlanguages\tpath\n"+
+		"1a2bc3d4ef\ttest\t\thttps://api.github.com/gists/1a2bc3d4ef\thttps://gist.github.com/1a2bc3d4ef.git\ttest-user\t1577836800\tfalse\t\t\t\t0\t0\t0\t\t\ttest\n",
+		buffer.String())
+}
+
+func TestFormatter_Format_that_FailsForErrorWriter(t *testing.T) {
+	for _, format := range []OutputFormat{"json", "xml", "yaml", "csv", "tsv"} {
+		formatter, err := NewFormatter(format)
+		assert.Nil(t, err)
+		err = formatter.Format(&ErrorWriter{}, formatterTestRecords)
+		assert.NotNil(t, err, string(format))
+	}
+}
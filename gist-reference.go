@@ -0,0 +1,66 @@
+package main
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// scpLikePattern matches scp-like git url(e.g. git@gist.github.com:{id}.git) up to its path.
+var scpLikePattern = regexp.MustCompile(`^[^/@]+@[^/:]+:`)
+
+// revisionLength is the length of sha1 hash of a revision.
+const revisionLength = 40
+
+// ParseGistReference normalizes a reference to a gist into GistID. Accepted forms are
+//   - id: `{id}`
+//   - owner and id: `{user}/{id}`
+//   - web url: `https://gist.github.com/{user}/{id}#file-main-go`, `https://{host}/gist/{user}/{id}/{revision}`
+//   - git url: `https://gist.github.com/{id}.git`, `git@gist.github.com:{id}.git`
+//   - raw url: `https://gist.githubusercontent.com/{user}/{id}/raw/{revision}/{file}`
+//   - api url: `https://api.github.com/gists/{id}`
+func ParseGistReference(reference string) (*GistID, error) {
+	path := strings.TrimSpace(reference)
+	if index := strings.IndexAny(path, "?#"); index >= 0 {
+		path = path[:index]
+	}
+	if index := strings.Index(path, "://"); index >= 0 {
+		path = path[index+3:]
+	} else if location := scpLikePattern.FindStringIndex(path); location != nil {
+		path = path[location[1]:]
+	}
+	segments := make([]string, 0)
+	for _, segment := range strings.Split(path, "/") {
+		if segment != "" {
+			segments = append(segments, strings.TrimSuffix(segment, ".git"))
+		}
+	}
+	id := gistIDSegment(segments)
+	if id == "" {
+		return nil, fmt.Errorf("invalid gist reference: %s", reference)
+	}
+	return NewGistID(id)
+}
+
+// gistIDSegment finds the segment of gist id from path segments of a reference.
+func gistIDSegment(segments []string) string {
+	for i, segment := range segments {
+		switch {
+		case segment == "raw" && 0 < i:
+			return segments[i-1]
+		case segment == "gists" && i+1 < len(segments):
+			return segments[i+1]
+		}
+	}
+	for i, segment := range segments {
+		if !idPattern.MatchString(segment) {
+			continue
+		}
+		// `{user}/{id}` when user name looks like hex, unless the next is a revision of `{id}/{revision}`.
+		if i+1 < len(segments) && idPattern.MatchString(segments[i+1]) && len(segments[i+1]) != revisionLength {
+			return segments[i+1]
+		}
+		return segment
+	}
+	return ""
+}
@@ -0,0 +1,62 @@
+package main
+
+import (
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestParseGistReference(t *testing.T) {
+	id := "0123456789abcdef0123456789abcdef"
+	revision := "fedcba9876543210fedcba9876543210fedcba98"
+	references := []string{
+		id,
+		" " + id + "\n",
+		"mike-neck/" + id,
+		"https://gist.github.com/" + id,
+		"https://gist.github.com/mike-neck/" + id,
+		"https://gist.github.com/mike-neck/" + id + "#file-main-go",
+		"https://gist.github.com/mike-neck/" + id + "/",
+		"https://gist.github.com/mike-neck/" + id + "/" + revision,
+		"https://gist.github.com/mike-neck/" + id + "/revisions?diff=split",
+		"https://gist.github.com/" + id + ".git",
+		"git@gist.github.com:" + id + ".git",
+		"ssh://git@gist.github.com/" + id + ".git",
+		"gist.github.com/mike-neck/" + id,
+		"https://gist.githubusercontent.com/mike-neck/" + id + "/raw/" + revision + "/main.go",
+		"https://gist.githubusercontent.com/mike-neck/" + id + "/raw/main.go",
+		"https://github.example.com/gist/mike-neck/" + id,
+		"https://github.example.com:8443/gist/" + id + ".git",
+		"https://github.example.com/gist/mike-neck/" + id + "/raw/" + revision + "/main.go",
+		"https://api.github.com/gists/" + id,
+		"https://github.example.com/api/v3/gists/" + id + "/comments",
+		// user name looking like hex
+		"https://gist.github.com/cafe/" + id,
+		"cafe/" + id,
+	}
+	for _, reference := range references {
+		gistID, err := ParseGistReference(reference)
+		if assert.Nil(t, err, reference) {
+			assert.Equal(t, GistID(id), *gistID, reference)
+		}
+	}
+}
+
+func TestParseGistReference_Invalid(t *testing.T) {
+	references := []string{
+		"",
+		"my-snippet",
+		"https://gist.github.com/mike-neck",
+		"https://github.com/mike-neck/gist.git",
+	}
+	for _, reference := range references {
+		_, err := ParseGistReference(reference)
+		assert.NotNil(t, err, reference)
+	}
+}
+
+func TestFindMetadata_ByReference(t *testing.T) {
+	items := []RepositoryMetadata{{ID: "aa11", Name: "first"}, {ID: "bb22"}}
+	assert.Equal(t, 0, FindMetadata(items, "first"))
+	assert.Equal(t, 1, FindMetadata(items, "https://gist.github.com/mike-neck/bb22#file-main-go"))
+	assert.Equal(t, -1, FindMetadata(items, "https://gist.github.com/mike-neck/cc33"))
+}
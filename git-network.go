@@ -0,0 +1,40 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"gopkg.in/src-d/go-git.v4/plumbing"
+	githttp "gopkg.in/src-d/go-git.v4/plumbing/transport/http"
+	"net/http"
+)
+
+// runGitOperation runs git operation over network, e.g. clone, pull or push, with timeout and retries of the profile.
+// Each attempt is given the timeout, and attempts failed by server error, timeout or temporary network error are retried.
+func runGitOperation(ctx context.Context, pctx ProfileContext, profileName ProfileName, name string, operation func(ctx context.Context) error) error {
+	timeout, err := pctx.Timeout(profileName)
+	if err != nil {
+		return err
+	}
+	retries, err := pctx.Retries(profileName)
+	if err != nil {
+		return err
+	}
+	return retryWithBackoff(ctx, retries, isRetryableGitError, name, func() error {
+		attemptCtx, cancel := context.WithTimeout(ctx, timeout)
+		defer cancel()
+		return operation(attemptCtx)
+	})
+}
+
+// isRetryableGitError tests git operation failed by server error, timeout or temporary network error.
+func isRetryableGitError(err error) bool {
+	var unexpected *plumbing.UnexpectedError
+	if errors.As(err, &unexpected) {
+		var httpError *githttp.Err
+		if errors.As(unexpected.Err, &httpError) {
+			return httpError.StatusCode() >= http.StatusInternalServerError
+		}
+		err = unexpected.Err
+	}
+	return isRetryable(err)
+}
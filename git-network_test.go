@@ -0,0 +1,57 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"github.com/stretchr/testify/assert"
+	"gopkg.in/src-d/go-git.v4"
+	"gopkg.in/src-d/go-git.v4/plumbing"
+	githttp "gopkg.in/src-d/go-git.v4/plumbing/transport/http"
+	"net"
+	"net/http"
+	"testing"
+	"time"
+)
+
+func gitNetworkTestContext() ProfileContext {
+	return ProfileContext{
+		CurrentProfiles: []Profile{{Name: "default", Timeout: "1s", Retries: 2}},
+	}
+}
+
+func TestRunGitOperation_RetriesTimeout(t *testing.T) {
+	original := retryBackoff
+	retryBackoff = time.Millisecond
+	defer func() { retryBackoff = original }()
+
+	attempts := 0
+	err := runGitOperation(context.Background(), gitNetworkTestContext(), "default", "pull", func(ctx context.Context) error {
+		attempts++
+		_, ok := ctx.Deadline()
+		assert.True(t, ok)
+		if attempts < 3 {
+			return &net.OpError{Op: "dial", Err: context.DeadlineExceeded}
+		}
+		return nil
+	})
+	assert.Nil(t, err)
+	assert.Equal(t, 3, attempts)
+}
+
+func TestRunGitOperation_NotRetried(t *testing.T) {
+	attempts := 0
+	err := runGitOperation(context.Background(), gitNetworkTestContext(), "default", "pull", func(ctx context.Context) error {
+		attempts++
+		return git.NoErrAlreadyUpToDate
+	})
+	assert.True(t, errors.Is(err, git.NoErrAlreadyUpToDate))
+	assert.Equal(t, 1, attempts)
+}
+
+func TestIsRetryableGitError(t *testing.T) {
+	serverError := plumbing.NewUnexpectedError(&githttp.Err{Response: &http.Response{StatusCode: http.StatusBadGateway}})
+	assert.True(t, isRetryableGitError(serverError))
+	clientError := plumbing.NewUnexpectedError(&githttp.Err{Response: &http.Response{StatusCode: http.StatusBadRequest}})
+	assert.False(t, isRetryableGitError(clientError))
+	assert.False(t, isRetryableGitError(git.ErrNonFastForwardUpdate))
+}
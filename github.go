@@ -1,25 +1,163 @@
 package main
 
 import (
+	"bytes"
+	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"io"
 	"io/ioutil"
+	"log"
+	"net"
 	"net/http"
+	"net/url"
+	"regexp"
+	"time"
 )
 
 // GitHub offers access to github.com
 type GitHub interface {
-	GetGist(gistID GistID, profileName ProfileName) (*Gist, error)
+	GetGist(ctx context.Context, gistID GistID, profileName ProfileName) (*Gist, error)
+	GetGistRevision(ctx context.Context, gistID GistID, version string, profileName ProfileName) (*Gist, error)
+	ListGists(ctx context.Context, query GistQuery, profileName ProfileName) ([]Gist, error)
+	CreateGist(ctx context.Context, newGist NewGist, profileName ProfileName) (*Gist, error)
+	DeleteGist(ctx context.Context, gistID GistID, profileName ProfileName) error
+	UpdateGist(ctx context.Context, gistID GistID, update GistUpdate, profileName ProfileName) (*Gist, error)
+	ForkGist(ctx context.Context, gistID GistID, profileName ProfileName) (*Gist, error)
+	ListComments(ctx context.Context, gistID GistID, profileName ProfileName) ([]Comment, error)
+	CreateComment(ctx context.Context, gistID GistID, body string, profileName ProfileName) (*Comment, error)
+	UpdateComment(ctx context.Context, gistID GistID, commentID CommentID, body string, profileName ProfileName) (*Comment, error)
+	DeleteComment(ctx context.Context, gistID GistID, commentID CommentID, profileName ProfileName) error
+	Star(ctx context.Context, gistID GistID, profileName ProfileName) error
+	Unstar(ctx context.Context, gistID GistID, profileName ProfileName) error
+	IsStarred(ctx context.Context, gistID GistID, profileName ProfileName) (bool, error)
+	ListStarredGists(ctx context.Context, since time.Time, profileName ProfileName) ([]Gist, error)
+}
+
+// NewGist is a request to create a gist.
+type NewGist struct {
+	Description string                 `json:"description,omitempty"`
+	Public      bool                   `json:"public"`
+	Files       map[string]GistContent `json:"files"`
+}
+
+// GistContent is contents of a file in a gist.
+type GistContent struct {
+	Content string `json:"content"`
+}
+
+// GistUpdate is a request to update a gist. Only given fields are changed.
+type GistUpdate struct {
+	Description *string `json:"description,omitempty"`
+	// Files are changes of files keyed by current file name. nil value deletes the file.
+	Files map[string]*GistFileUpdate `json:"files,omitempty"`
+}
+
+// GistFileUpdate is a change of a file in a gist.
+type GistFileUpdate struct {
+	// Content is new contents of the file. If empty, contents are not changed.
+	Content string `json:"content,omitempty"`
+	// Filename is new name of the file. If empty, the file is not renamed.
+	Filename string `json:"filename,omitempty"`
+}
+
+// GistQuery is a condition of gists to be listed.
+type GistQuery struct {
+	// User is login name of owner. If empty, gists of the authenticated user will be listed.
+	User string
+	// Starred lists starred gists of the authenticated user instead.
+	Starred bool
+	// Since lists only gists updated at or after this time. Zero value means no filtering.
+	Since time.Time
+}
+
+func (q *GistQuery) requestURL(baseURL APIBaseURL) string {
+	path := "/gists"
+	if q.Starred {
+		path = "/gists/starred"
+	} else if q.User != "" {
+		path = fmt.Sprintf("/users/%s/gists", url.PathEscape(q.User))
+	}
+	values := url.Values{}
+	values.Set("per_page", "100")
+	if !q.Since.IsZero() {
+		values.Set("since", q.Since.UTC().Format("2006-01-02T15:04:05Z"))
+	}
+	return fmt.Sprintf("%s%s?%s", baseURL, path, values.Encode())
 }
 
 // Gist represents gist API response, some of them are omitted.
 type Gist struct {
-	URL         string     `json:"url"`
-	GitURL      string     `json:"git_pull_url"`
-	ID          string     `json:"id"`
-	Description string     `json:"description"`
-	CreatedAt   string     `json:"created_at"`
-	Owner       GitHubUser `json:"owner"`
+	URL         string              `json:"url"`
+	HTMLURL     string              `json:"html_url"`
+	GitURL      string              `json:"git_pull_url"`
+	ID          string              `json:"id"`
+	Description string              `json:"description"`
+	Public      bool                `json:"public"`
+	CreatedAt   string              `json:"created_at"`
+	UpdatedAt   string              `json:"updated_at"`
+	Owner       GitHubUser          `json:"owner"`
+	Comments    int                 `json:"comments"`
+	ForkOf      *ParentGist         `json:"fork_of"`
+	Forks       []GistFork          `json:"forks"`
+	Files       map[string]GistFile `json:"files"`
+	History     []GistRevision      `json:"history"`
+}
+
+// GistFile is a file in gist. Content is available only in a response of a single gist.
+type GistFile struct {
+	Filename  string `json:"filename"`
+	Language  string `json:"language"`
+	Type      string `json:"type"`
+	Size      int64  `json:"size"`
+	RawURL    string `json:"raw_url"`
+	Truncated bool   `json:"truncated"`
+	Content   string `json:"content"`
+}
+
+// GistFork is a fork of gist.
+type GistFork struct {
+	ID        string     `json:"id"`
+	User      GitHubUser `json:"user"`
+	CreatedAt string     `json:"created_at"`
+}
+
+// GistRevision is a revision in history of gist.
+type GistRevision struct {
+	Version      string           `json:"version"`
+	CommittedAt  string           `json:"committed_at"`
+	ChangeStatus GistChangeStatus `json:"change_status"`
+	User         GitHubUser       `json:"user"`
+}
+
+// GistChangeStatus is numbers of changed lines in a revision.
+type GistChangeStatus struct {
+	Total     int `json:"total"`
+	Additions int `json:"additions"`
+	Deletions int `json:"deletions"`
+}
+
+// CommentID is id of a comment on gist.
+type CommentID int64
+
+// Comment is a comment on gist.
+type Comment struct {
+	ID        CommentID  `json:"id"`
+	Body      string     `json:"body"`
+	User      GitHubUser `json:"user"`
+	CreatedAt string     `json:"created_at"`
+	UpdatedAt string     `json:"updated_at"`
+}
+
+// commentRequest is a request body to create or update a comment.
+type commentRequest struct {
+	Body string `json:"body"`
+}
+
+// ParentGist is a gist from which a gist is forked.
+type ParentGist struct {
+	ID string `json:"id"`
 }
 
 // GitHubUser is github user.
@@ -28,9 +166,11 @@ type GitHubUser struct {
 }
 
 var githubAPIBaseURL = "https://api.github.com"
+var defaultGistHost GistHost = "gist.github.com"
 var acceptHeader string = "application/vnd.github.v3+json"
 
 // NewGitHub create github data from current ProfileContext.
+// API base url is determined for each profile.
 func (context *ProfileContext) NewGitHub() GitHub {
 	return &gitHubImpl{*context}
 }
@@ -39,38 +179,471 @@ type gitHubImpl struct {
 	ProfileContext
 }
 
-func (gh *gitHubImpl) GetGist(gistID GistID, profileName ProfileName) (*Gist, error) {
-	client := http.Client{}
-	request, err := http.NewRequest("GET", fmt.Sprintf("%s/gists/%s", githubAPIBaseURL, gistID), nil)
+func (gh *gitHubImpl) GetGist(ctx context.Context, gistID GistID, profileName ProfileName) (*Gist, error) {
+	baseURL, err := gh.APIBaseURL(profileName)
 	if err != nil {
-		return nil, fmt.Errorf("GitHub_GetGist_NewRequest: %w", err)
+		return nil, fmt.Errorf("GitHub_GetGist_APIBaseURL: %w", err)
 	}
-	accessToken, err := gh.Token(profileName)
+	response, bytes, err := gh.get(ctx, fmt.Sprintf("%s/gists/%s", baseURL, gistID), profileName)
 	if err != nil {
-		return nil, fmt.Errorf("GitHub_GetGist_Token: %w", err)
+		return nil, fmt.Errorf("GitHub_GetGist: %w", err)
 	}
-	request.Header.Add("authorization", fmt.Sprintf("Bearer %s", accessToken))
-	request.Header.Add("accept", acceptHeader)
-	response, err := client.Do(request)
+	err = checkResponse(response, bytes)
 	if err != nil {
-		return nil, fmt.Errorf("GitHub_GetGist_DoRequest: %w", err)
+		return nil, fmt.Errorf("failed to get gist info(%s): %w", gistID, err)
 	}
-	defer func() { _ = response.Body.Close() }()
-	sc := response.StatusCode
-	if sc < 200 || 300 <= sc {
-		return nil, fmt.Errorf("failed to get gist info(%s, http status:%s)", gistID, response.Status)
+
+	var gist Gist
+	err = json.Unmarshal(bytes, &gist)
+	if err != nil {
+		return nil, fmt.Errorf("GitHub_GetGist_JsonUnmarshal: %w", err)
 	}
 
-	bytes, err := ioutil.ReadAll(response.Body)
+	return &gist, nil
+}
+
+func (gh *gitHubImpl) GetGistRevision(ctx context.Context, gistID GistID, version string, profileName ProfileName) (*Gist, error) {
+	baseURL, err := gh.APIBaseURL(profileName)
 	if err != nil {
-		return nil, fmt.Errorf("GitHub_GetGist_ReadAll: %w", err)
+		return nil, fmt.Errorf("GitHub_GetGistRevision_APIBaseURL: %w", err)
+	}
+	response, bytes, err := gh.get(ctx, fmt.Sprintf("%s/gists/%s/%s", baseURL, gistID, version), profileName)
+	if err != nil {
+		return nil, fmt.Errorf("GitHub_GetGistRevision: %w", err)
+	}
+	err = checkResponse(response, bytes)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get gist revision(%s/%s): %w", gistID, version, err)
 	}
 
 	var gist Gist
 	err = json.Unmarshal(bytes, &gist)
 	if err != nil {
-		return nil, fmt.Errorf("GitHub_GetGist_JsonUnmarshal: %w", err)
+		return nil, fmt.Errorf("GitHub_GetGistRevision_JsonUnmarshal: %w", err)
+	}
+
+	return &gist, nil
+}
+
+func (gh *gitHubImpl) ListGists(ctx context.Context, query GistQuery, profileName ProfileName) ([]Gist, error) {
+	baseURL, err := gh.APIBaseURL(profileName)
+	if err != nil {
+		return nil, fmt.Errorf("GitHub_ListGists_APIBaseURL: %w", err)
+	}
+	gists := make([]Gist, 0)
+	pageURL := query.requestURL(baseURL)
+	for pageURL != "" {
+		response, bytes, err := gh.get(ctx, pageURL, profileName)
+		if err != nil {
+			return nil, fmt.Errorf("GitHub_ListGists: %w", err)
+		}
+		err = checkResponse(response, bytes)
+		if err != nil {
+			return nil, fmt.Errorf("failed to list gists(%s): %w", pageURL, err)
+		}
+		var page []Gist
+		err = json.Unmarshal(bytes, &page)
+		if err != nil {
+			return nil, fmt.Errorf("GitHub_ListGists_JsonUnmarshal: %w", err)
+		}
+		gists = append(gists, page...)
+		pageURL = nextPageURL(response.Header.Get("link"))
+	}
+	return gists, nil
+}
+
+func (gh *gitHubImpl) CreateGist(ctx context.Context, newGist NewGist, profileName ProfileName) (*Gist, error) {
+	baseURL, err := gh.APIBaseURL(profileName)
+	if err != nil {
+		return nil, fmt.Errorf("GitHub_CreateGist_APIBaseURL: %w", err)
+	}
+	response, bytes, err := gh.send(ctx, "POST", fmt.Sprintf("%s/gists", baseURL), profileName, newGist)
+	if err != nil {
+		return nil, fmt.Errorf("GitHub_CreateGist: %w", err)
+	}
+	err = checkResponse(response, bytes)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create gist: %w", err)
+	}
+
+	var gist Gist
+	err = json.Unmarshal(bytes, &gist)
+	if err != nil {
+		return nil, fmt.Errorf("GitHub_CreateGist_JsonUnmarshal: %w", err)
 	}
 
 	return &gist, nil
 }
+
+func (gh *gitHubImpl) DeleteGist(ctx context.Context, gistID GistID, profileName ProfileName) error {
+	baseURL, err := gh.APIBaseURL(profileName)
+	if err != nil {
+		return fmt.Errorf("GitHub_DeleteGist_APIBaseURL: %w", err)
+	}
+	response, bytes, err := gh.send(ctx, "DELETE", fmt.Sprintf("%s/gists/%s", baseURL, gistID), profileName, nil)
+	if err != nil {
+		return fmt.Errorf("GitHub_DeleteGist: %w", err)
+	}
+	err = checkResponse(response, bytes)
+	if err != nil {
+		return fmt.Errorf("failed to delete gist(%s): %w", gistID, err)
+	}
+	return nil
+}
+
+func (gh *gitHubImpl) UpdateGist(ctx context.Context, gistID GistID, update GistUpdate, profileName ProfileName) (*Gist, error) {
+	baseURL, err := gh.APIBaseURL(profileName)
+	if err != nil {
+		return nil, fmt.Errorf("GitHub_UpdateGist_APIBaseURL: %w", err)
+	}
+	response, bytes, err := gh.send(ctx, "PATCH", fmt.Sprintf("%s/gists/%s", baseURL, gistID), profileName, update)
+	if err != nil {
+		return nil, fmt.Errorf("GitHub_UpdateGist: %w", err)
+	}
+	err = checkResponse(response, bytes)
+	if err != nil {
+		return nil, fmt.Errorf("failed to update gist(%s): %w", gistID, err)
+	}
+
+	var gist Gist
+	err = json.Unmarshal(bytes, &gist)
+	if err != nil {
+		return nil, fmt.Errorf("GitHub_UpdateGist_JsonUnmarshal: %w", err)
+	}
+
+	return &gist, nil
+}
+
+func (gh *gitHubImpl) ForkGist(ctx context.Context, gistID GistID, profileName ProfileName) (*Gist, error) {
+	baseURL, err := gh.APIBaseURL(profileName)
+	if err != nil {
+		return nil, fmt.Errorf("GitHub_ForkGist_APIBaseURL: %w", err)
+	}
+	response, bytes, err := gh.send(ctx, "POST", fmt.Sprintf("%s/gists/%s/forks", baseURL, gistID), profileName, nil)
+	if err != nil {
+		return nil, fmt.Errorf("GitHub_ForkGist: %w", err)
+	}
+	err = checkResponse(response, bytes)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fork gist(%s): %w", gistID, err)
+	}
+
+	var gist Gist
+	err = json.Unmarshal(bytes, &gist)
+	if err != nil {
+		return nil, fmt.Errorf("GitHub_ForkGist_JsonUnmarshal: %w", err)
+	}
+
+	return &gist, nil
+}
+
+func (gh *gitHubImpl) Star(ctx context.Context, gistID GistID, profileName ProfileName) error {
+	return gh.sendStar(ctx, "PUT", gistID, profileName)
+}
+
+func (gh *gitHubImpl) Unstar(ctx context.Context, gistID GistID, profileName ProfileName) error {
+	return gh.sendStar(ctx, "DELETE", gistID, profileName)
+}
+
+func (gh *gitHubImpl) sendStar(ctx context.Context, method string, gistID GistID, profileName ProfileName) error {
+	baseURL, err := gh.APIBaseURL(profileName)
+	if err != nil {
+		return fmt.Errorf("GitHub_Star_APIBaseURL: %w", err)
+	}
+	response, bytes, err := gh.send(ctx, method, fmt.Sprintf("%s/gists/%s/star", baseURL, gistID), profileName, nil)
+	if err != nil {
+		return fmt.Errorf("GitHub_Star: %w", err)
+	}
+	err = checkResponse(response, bytes)
+	if err != nil {
+		return fmt.Errorf("failed to star/unstar gist(%s %s): %w", method, gistID, err)
+	}
+	return nil
+}
+
+func (gh *gitHubImpl) IsStarred(ctx context.Context, gistID GistID, profileName ProfileName) (bool, error) {
+	baseURL, err := gh.APIBaseURL(profileName)
+	if err != nil {
+		return false, fmt.Errorf("GitHub_IsStarred_APIBaseURL: %w", err)
+	}
+	response, bytes, err := gh.get(ctx, fmt.Sprintf("%s/gists/%s/star", baseURL, gistID), profileName)
+	if err != nil {
+		return false, fmt.Errorf("GitHub_IsStarred: %w", err)
+	}
+	if response.StatusCode == http.StatusNotFound {
+		return false, nil
+	}
+	err = checkResponse(response, bytes)
+	if err != nil {
+		return false, fmt.Errorf("failed to check star of gist(%s): %w", gistID, err)
+	}
+	return true, nil
+}
+
+func (gh *gitHubImpl) ListStarredGists(ctx context.Context, since time.Time, profileName ProfileName) ([]Gist, error) {
+	return gh.ListGists(ctx, GistQuery{Starred: true, Since: since}, profileName)
+}
+
+func (gh *gitHubImpl) ListComments(ctx context.Context, gistID GistID, profileName ProfileName) ([]Comment, error) {
+	baseURL, err := gh.APIBaseURL(profileName)
+	if err != nil {
+		return nil, fmt.Errorf("GitHub_ListComments_APIBaseURL: %w", err)
+	}
+	comments := make([]Comment, 0)
+	pageURL := fmt.Sprintf("%s/gists/%s/comments?per_page=100", baseURL, gistID)
+	for pageURL != "" {
+		response, bytes, err := gh.get(ctx, pageURL, profileName)
+		if err != nil {
+			return nil, fmt.Errorf("GitHub_ListComments: %w", err)
+		}
+		err = checkResponse(response, bytes)
+		if err != nil {
+			return nil, fmt.Errorf("failed to list comments(%s): %w", gistID, err)
+		}
+		var page []Comment
+		err = json.Unmarshal(bytes, &page)
+		if err != nil {
+			return nil, fmt.Errorf("GitHub_ListComments_JsonUnmarshal: %w", err)
+		}
+		comments = append(comments, page...)
+		pageURL = nextPageURL(response.Header.Get("link"))
+	}
+	return comments, nil
+}
+
+func (gh *gitHubImpl) CreateComment(ctx context.Context, gistID GistID, body string, profileName ProfileName) (*Comment, error) {
+	baseURL, err := gh.APIBaseURL(profileName)
+	if err != nil {
+		return nil, fmt.Errorf("GitHub_CreateComment_APIBaseURL: %w", err)
+	}
+	requestURL := fmt.Sprintf("%s/gists/%s/comments", baseURL, gistID)
+	response, bytes, err := gh.send(ctx, "POST", requestURL, profileName, commentRequest{Body: body})
+	if err != nil {
+		return nil, fmt.Errorf("GitHub_CreateComment: %w", err)
+	}
+	err = checkResponse(response, bytes)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create comment(%s): %w", gistID, err)
+	}
+
+	var comment Comment
+	err = json.Unmarshal(bytes, &comment)
+	if err != nil {
+		return nil, fmt.Errorf("GitHub_CreateComment_JsonUnmarshal: %w", err)
+	}
+	return &comment, nil
+}
+
+func (gh *gitHubImpl) UpdateComment(ctx context.Context, gistID GistID, commentID CommentID, body string, profileName ProfileName) (*Comment, error) {
+	baseURL, err := gh.APIBaseURL(profileName)
+	if err != nil {
+		return nil, fmt.Errorf("GitHub_UpdateComment_APIBaseURL: %w", err)
+	}
+	requestURL := fmt.Sprintf("%s/gists/%s/comments/%d", baseURL, gistID, commentID)
+	response, bytes, err := gh.send(ctx, "PATCH", requestURL, profileName, commentRequest{Body: body})
+	if err != nil {
+		return nil, fmt.Errorf("GitHub_UpdateComment: %w", err)
+	}
+	err = checkResponse(response, bytes)
+	if err != nil {
+		return nil, fmt.Errorf("failed to update comment(%s/%d): %w", gistID, commentID, err)
+	}
+
+	var comment Comment
+	err = json.Unmarshal(bytes, &comment)
+	if err != nil {
+		return nil, fmt.Errorf("GitHub_UpdateComment_JsonUnmarshal: %w", err)
+	}
+	return &comment, nil
+}
+
+func (gh *gitHubImpl) DeleteComment(ctx context.Context, gistID GistID, commentID CommentID, profileName ProfileName) error {
+	baseURL, err := gh.APIBaseURL(profileName)
+	if err != nil {
+		return fmt.Errorf("GitHub_DeleteComment_APIBaseURL: %w", err)
+	}
+	requestURL := fmt.Sprintf("%s/gists/%s/comments/%d", baseURL, gistID, commentID)
+	response, bytes, err := gh.send(ctx, "DELETE", requestURL, profileName, nil)
+	if err != nil {
+		return fmt.Errorf("GitHub_DeleteComment: %w", err)
+	}
+	err = checkResponse(response, bytes)
+	if err != nil {
+		return fmt.Errorf("failed to delete comment(%s/%d): %w", gistID, commentID, err)
+	}
+	return nil
+}
+
+// get sends GET request with the access token of the profile, and returns response with its body.
+// Responses with ETag or Last-Modified are cached, and 304 response is served from the cache.
+func (gh *gitHubImpl) get(ctx context.Context, requestURL string, profileName ProfileName) (*http.Response, []byte, error) {
+	cacheDir, err := gh.CacheDir(profileName)
+	if err != nil {
+		return nil, nil, fmt.Errorf("GitHub_Get_CacheDir: %w", err)
+	}
+	cache := ResponseCache{CacheDir: cacheDir}
+	request, err := gh.newRequest(ctx, "GET", requestURL, profileName, nil)
+	if err != nil {
+		return nil, nil, err
+	}
+	entry := cache.Load(requestURL)
+	if entry != nil {
+		entry.conditions(request)
+	}
+	response, body, err := gh.do(request, profileName)
+	if err != nil {
+		return nil, nil, err
+	}
+	if entry != nil && response.StatusCode == http.StatusNotModified {
+		entry.restore(response)
+		return response, entry.Body, nil
+	}
+	err = cache.Store(requestURL, response, body)
+	if err != nil {
+		log.Printf("failed to cache response of %s: %v\n", requestURL, err)
+	}
+	return response, body, nil
+}
+
+// send sends request with the access token of the profile, and returns response with its body.
+// If body is not nil, it is sent as json.
+func (gh *gitHubImpl) send(ctx context.Context, method string, requestURL string, profileName ProfileName, body interface{}) (*http.Response, []byte, error) {
+	request, err := gh.newRequest(ctx, method, requestURL, profileName, body)
+	if err != nil {
+		return nil, nil, err
+	}
+	return gh.do(request, profileName)
+}
+
+// newRequest creates request with the access token of the profile.
+func (gh *gitHubImpl) newRequest(ctx context.Context, method string, requestURL string, profileName ProfileName, body interface{}) (*http.Request, error) {
+	var reader io.Reader
+	if body != nil {
+		bs, err := json.Marshal(body)
+		if err != nil {
+			return nil, fmt.Errorf("GitHub_Send_MarshalJson: %w", err)
+		}
+		reader = bytes.NewReader(bs)
+	}
+	request, err := http.NewRequestWithContext(ctx, method, requestURL, reader)
+	if err != nil {
+		return nil, fmt.Errorf("GitHub_Send_NewRequest: %w", err)
+	}
+	accessToken, err := gh.Token(profileName)
+	if err != nil {
+		return nil, fmt.Errorf("GitHub_Send_Token: %w", err)
+	}
+	request.Header.Add("authorization", fmt.Sprintf("Bearer %s", accessToken))
+	request.Header.Add("accept", acceptHeader)
+	if body != nil {
+		request.Header.Add("content-type", "application/json")
+	}
+	return request, nil
+}
+
+// do sends request, and returns response with its body.
+// Requests failed by server error, timeout or temporary network error are retried with exponential backoff, except for POST.
+func (gh *gitHubImpl) do(request *http.Request, profileName ProfileName) (*http.Response, []byte, error) {
+	timeout, err := gh.Timeout(profileName)
+	if err != nil {
+		return nil, nil, fmt.Errorf("GitHub_Send_Timeout: %w", err)
+	}
+	retries, err := gh.Retries(profileName)
+	if err != nil {
+		return nil, nil, fmt.Errorf("GitHub_Send_Retries: %w", err)
+	}
+	if request.Method == "POST" {
+		retries = 0
+	}
+	client := http.Client{Timeout: timeout}
+	var response *http.Response
+	var bs []byte
+	attempts := 0
+	err = retryWithBackoff(request.Context(), retries, isRetryable, fmt.Sprintf("%s %s", request.Method, request.URL), func() error {
+		if attempts > 0 && request.GetBody != nil {
+			body, err := request.GetBody()
+			if err != nil {
+				return fmt.Errorf("GitHub_Send_GetBody: %w", err)
+			}
+			request.Body = body
+		}
+		attempts++
+		var err error
+		response, bs, err = gh.doOnce(&client, request)
+		if err != nil {
+			return err
+		}
+		// other errors of response are handled by callers
+		var serverError *ServerError
+		if err = checkResponse(response, bs); errors.As(err, &serverError) {
+			return err
+		}
+		return nil
+	})
+	if response != nil {
+		return response, bs, nil
+	}
+	return nil, nil, err
+}
+
+// retryWithBackoff calls try until it succeeds or fails by an error which is not retryable, at most retries times again.
+// Intervals of retries start at retryBackoff and are doubled on each retry. Retries stop when ctx is done.
+func retryWithBackoff(ctx context.Context, retries int, retryable func(err error) bool, name string, try func() error) error {
+	for attempt := 0; ; attempt++ {
+		err := try()
+		if err == nil || !retryable(err) || attempt >= retries || ctx.Err() != nil {
+			return err
+		}
+		wait := retryBackoff << uint(attempt)
+		log.Printf("%s failed(%v), retrying in %s\n", name, err, wait)
+		select {
+		case <-ctx.Done():
+			return fmt.Errorf("%s: %w", name, ctx.Err())
+		case <-time.After(wait):
+		}
+	}
+}
+
+// isRetryable tests the request failed by server error, timeout or temporary network error.
+// Errors which are not resolved by retries, e.g. unknown host, are not retried.
+func isRetryable(err error) bool {
+	var serverError *ServerError
+	if errors.As(err, &serverError) {
+		return true
+	}
+	var dnsError *net.DNSError
+	if errors.As(err, &dnsError) && dnsError.IsNotFound {
+		return false
+	}
+	var netError net.Error
+	return errors.As(err, &netError) && (netError.Timeout() || netError.Temporary())
+}
+
+// retryBackoff is the first interval of retries, which is doubled on each retry.
+var retryBackoff = 500 * time.Millisecond
+
+func (gh *gitHubImpl) doOnce(client *http.Client, request *http.Request) (*http.Response, []byte, error) {
+	response, err := client.Do(request)
+	if err != nil {
+		return nil, nil, fmt.Errorf("GitHub_Send_DoRequest: %w", err)
+	}
+	defer func() { _ = response.Body.Close() }()
+
+	bs, err := ioutil.ReadAll(response.Body)
+	if err != nil {
+		return nil, nil, fmt.Errorf("GitHub_Send_ReadAll: %w", err)
+	}
+	return response, bs, nil
+}
+
+var linkPattern = regexp.MustCompile(`<([^>]+)>;\s*rel="([^"]+)"`)
+
+// nextPageURL returns url of next page from Link header. If there is no next page, empty string will be returned.
+func nextPageURL(link string) string {
+	for _, match := range linkPattern.FindAllStringSubmatch(link, -1) {
+		if match[2] == "next" {
+			return match[1]
+		}
+	}
+	return ""
+}
@@ -1,10 +1,19 @@
 package main
 
 import (
+	"context"
+	"errors"
+	"fmt"
 	"github.com/joho/godotenv"
 	"github.com/stretchr/testify/assert"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
 	"runtime"
+	"syscall"
 	"testing"
+	"time"
 )
 
 func TestGitHubImpl_GetGist_Success(t *testing.T) {
@@ -18,13 +27,13 @@ func TestGitHubImpl_GetGist_Success(t *testing.T) {
 		return
 	}
 	envValues := NewEnvValues()
-	context, err := envValues.NewContext(ProfileFile("testdata/github-test.yml"))
+	ctx, err := envValues.NewContext(ProfileFile("testdata/github-test.yml"))
 	if err != nil {
 		assert.Fail(t, "context creation failed", err)
 		return
 	}
-	gitHub := context.NewGitHub()
-	gist, err := gitHub.GetGist(GistID("d1b910d36d314b77b057ea66fbb65e81"), ProfileName("default"))
+	gitHub := ctx.NewGitHub()
+	gist, err := gitHub.GetGist(context.Background(), GistID("d1b910d36d314b77b057ea66fbb65e81"), ProfileName("default"))
 	assert.Nil(t, err)
 	assert.Equal(t, "https://api.github.com/gists/d1b910d36d314b77b057ea66fbb65e81", gist.URL)
 	assert.Equal(t, "mike-neck", gist.Owner.Login)
@@ -41,13 +50,231 @@ func TestGitHubImpl_GetGist_404(t *testing.T) {
 		return
 	}
 	envValues := NewEnvValues()
-	context, err := envValues.NewContext(ProfileFile("testdata/github-test.yml"))
+	ctx, err := envValues.NewContext(ProfileFile("testdata/github-test.yml"))
 	if err != nil {
 		assert.Fail(t, "context creation failed", err)
 		return
 	}
-	gitHub := context.NewGitHub()
-	gist, err := gitHub.GetGist(GistID("https://api.github.com/gists/aaaaaaaaaaaa"), ProfileName("default"))
+	gitHub := ctx.NewGitHub()
+	gist, err := gitHub.GetGist(context.Background(), GistID("https://api.github.com/gists/aaaaaaaaaaaa"), ProfileName("default"))
 	assert.NotNil(t, err)
 	assert.Nil(t, gist)
 }
+
+// startTestGitHubServer starts a fake GitHub API server, and returns ProfileContext for it and function to stop it.
+func startTestGitHubServer(handler http.HandlerFunc) (ProfileContext, func()) {
+	server := httptest.NewServer(handler)
+	original := githubAPIBaseURL
+	githubAPIBaseURL = server.URL
+	ctx := ProfileContext{
+		CurrentProfiles: []Profile{
+			{
+				Name:  "default",
+				Token: "aa00bb11cc22",
+				Dir:   "build/test/github",
+			},
+		},
+	}
+	return ctx, func() {
+		githubAPIBaseURL = original
+		server.Close()
+	}
+}
+
+func TestGitHubImpl_ListGists_FollowsLinkHeader(t *testing.T) {
+	var serverURL string
+	ctx, stop := startTestGitHubServer(func(writer http.ResponseWriter, request *http.Request) {
+		assert.Equal(t, "Bearer aa00bb11cc22", request.Header.Get("authorization"))
+		assert.Equal(t, "/gists", request.URL.Path)
+		if request.URL.Query().Get("page") == "2" {
+			_, _ = writer.Write([]byte(`[{"id":"cc33"}]`))
+			return
+		}
+		writer.Header().Set("link", fmt.Sprintf(`<%s/gists?page=2>; rel="next", <%s/gists?page=2>; rel="last"`, serverURL, serverURL))
+		_, _ = writer.Write([]byte(`[{"id":"aa11"},{"id":"bb22"}]`))
+	})
+	defer stop()
+	serverURL = githubAPIBaseURL
+
+	gists, err := ctx.NewGitHub().ListGists(context.Background(), GistQuery{}, "default")
+	assert.Nil(t, err)
+	ids := make([]string, len(gists))
+	for i, gist := range gists {
+		ids[i] = gist.ID
+	}
+	assert.Equal(t, []string{"aa11", "bb22", "cc33"}, ids)
+}
+
+func TestGitHubImpl_ListGists_UserSince(t *testing.T) {
+	ctx, stop := startTestGitHubServer(func(writer http.ResponseWriter, request *http.Request) {
+		assert.Equal(t, "/users/mike-neck/gists", request.URL.Path)
+		assert.Equal(t, "2020-01-02T03:04:05Z", request.URL.Query().Get("since"))
+		_, _ = writer.Write([]byte(`[]`))
+	})
+	defer stop()
+
+	since := time.Date(2020, 1, 2, 3, 4, 5, 0, time.UTC)
+	gists, err := ctx.NewGitHub().ListGists(context.Background(), GistQuery{User: "mike-neck", Since: since}, "default")
+	assert.Nil(t, err)
+	assert.Equal(t, 0, len(gists))
+}
+
+func TestGitHubImpl_ListGists_Error(t *testing.T) {
+	ctx, stop := startTestGitHubServer(func(writer http.ResponseWriter, request *http.Request) {
+		assert.Equal(t, "/gists/starred", request.URL.Path)
+		writer.WriteHeader(http.StatusUnauthorized)
+	})
+	defer stop()
+
+	gists, err := ctx.NewGitHub().ListGists(context.Background(), GistQuery{Starred: true}, "default")
+	assert.NotNil(t, err)
+	assert.Nil(t, gists)
+}
+
+func TestNextPageURL(t *testing.T) {
+	link := `<https://api.github.com/gists?page=3>; rel="next", <https://api.github.com/gists?page=1>; rel="first"`
+	assert.Equal(t, "https://api.github.com/gists?page=3", nextPageURL(link))
+	assert.Equal(t, "", nextPageURL(`<https://api.github.com/gists?page=1>; rel="prev"`))
+	assert.Equal(t, "", nextPageURL(""))
+}
+
+func TestGitHubImpl_GetGist_ProfileAPIBaseURL(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+		assert.Equal(t, "/api/v3/gists/aa11", request.URL.Path)
+		_, _ = writer.Write([]byte(`{"id":"aa11","owner":{"login":"test-user"}}`))
+	}))
+	defer server.Close()
+	ctx := ProfileContext{
+		CurrentProfiles: []Profile{
+			{
+				Name:       "enterprise",
+				Token:      "aa00bb11cc22",
+				APIBaseURL: APIBaseURL(server.URL + "/api/v3"),
+			},
+		},
+	}
+
+	gist, err := ctx.NewGitHub().GetGist(context.Background(), "aa11", "enterprise")
+	assert.Nil(t, err)
+	assert.Equal(t, "test-user", gist.Owner.Login)
+}
+
+func TestGitHubImpl_IsStarred(t *testing.T) {
+	ctx, stop := startTestGitHubServer(func(writer http.ResponseWriter, request *http.Request) {
+		assert.Equal(t, "GET", request.Method)
+		if request.URL.Path == "/gists/aa11/star" {
+			writer.WriteHeader(http.StatusNoContent)
+			return
+		}
+		writer.WriteHeader(http.StatusNotFound)
+	})
+	defer stop()
+	gitHub := ctx.NewGitHub()
+	starred, err := gitHub.IsStarred(context.Background(), "aa11", "default")
+	assert.Nil(t, err)
+	assert.True(t, starred)
+	starred, err = gitHub.IsStarred(context.Background(), "bb22", "default")
+	assert.Nil(t, err)
+	assert.False(t, starred)
+}
+
+func TestGitHubImpl_ListStarredGists(t *testing.T) {
+	ctx, stop := startTestGitHubServer(func(writer http.ResponseWriter, request *http.Request) {
+		assert.Equal(t, "/gists/starred", request.URL.Path)
+		_, _ = writer.Write([]byte(`[{"id":"aa11"}]`))
+	})
+	defer stop()
+	gists, err := ctx.NewGitHub().ListStarredGists(context.Background(), time.Time{}, "default")
+	assert.Nil(t, err)
+	assert.Equal(t, 1, len(gists))
+	assert.Equal(t, "aa11", gists[0].ID)
+}
+
+func TestGitHubImpl_GetGist_RetriesServerError(t *testing.T) {
+	original := retryBackoff
+	retryBackoff = time.Millisecond
+	defer func() { retryBackoff = original }()
+	requests := 0
+	ctx, stop := startTestGitHubServer(func(writer http.ResponseWriter, request *http.Request) {
+		requests++
+		if requests < 3 {
+			writer.WriteHeader(http.StatusBadGateway)
+			return
+		}
+		_, _ = writer.Write([]byte(`{"id":"aa11"}`))
+	})
+	defer stop()
+
+	gist, err := ctx.NewGitHub().GetGist(context.Background(), "aa11", "default")
+	assert.Nil(t, err)
+	assert.Equal(t, "aa11", gist.ID)
+	assert.Equal(t, 3, requests)
+}
+
+func TestGitHubImpl_GetGist_GivesUpRetries(t *testing.T) {
+	original := retryBackoff
+	retryBackoff = time.Millisecond
+	defer func() { retryBackoff = original }()
+	requests := 0
+	ctx, stop := startTestGitHubServer(func(writer http.ResponseWriter, request *http.Request) {
+		requests++
+		writer.WriteHeader(http.StatusServiceUnavailable)
+	})
+	defer stop()
+
+	_, err := ctx.NewGitHub().GetGist(context.Background(), "aa11", "default")
+	var serverError *ServerError
+	assert.True(t, errors.As(err, &serverError), err)
+	assert.Equal(t, 4, requests)
+}
+
+func TestGitHubImpl_CreateGist_NotRetried(t *testing.T) {
+	original := retryBackoff
+	retryBackoff = time.Millisecond
+	defer func() { retryBackoff = original }()
+	requests := 0
+	ctx, stop := startTestGitHubServer(func(writer http.ResponseWriter, request *http.Request) {
+		requests++
+		writer.WriteHeader(http.StatusInternalServerError)
+	})
+	defer stop()
+
+	_, err := ctx.NewGitHub().CreateGist(context.Background(), NewGist{}, "default")
+	assert.NotNil(t, err)
+	assert.Equal(t, 1, requests)
+}
+
+func TestGitHubImpl_GetGist_Timeout(t *testing.T) {
+	ctx, stop := startTestGitHubServer(func(writer http.ResponseWriter, request *http.Request) {
+		time.Sleep(200 * time.Millisecond)
+	})
+	defer stop()
+	ctx.CurrentProfiles[0].Timeout = "10ms"
+	ctx.CurrentProfiles[0].Retries = -1
+
+	_, err := ctx.NewGitHub().GetGist(context.Background(), "aa11", "default")
+	assert.NotNil(t, err)
+}
+
+func TestIsRetryable(t *testing.T) {
+	wrap := func(err error) error {
+		return fmt.Errorf("GitHub_Send_DoRequest: %w", &url.Error{Op: "Get", URL: "https://api.github.com/gists/aa11", Err: err})
+	}
+	assert.True(t, isRetryable(&ServerError{&APIError{StatusCode: 502}}))
+	assert.True(t, isRetryable(wrap(&net.DNSError{Err: "i/o timeout", Name: "api.github.com", IsTimeout: true})))
+	assert.False(t, isRetryable(wrap(&net.DNSError{Err: "no such host", Name: "api.github.invalid", IsNotFound: true})))
+	assert.False(t, isRetryable(wrap(&net.OpError{Op: "dial", Net: "tcp", Err: syscall.ECONNREFUSED})))
+	assert.False(t, isRetryable(&NotFoundError{&APIError{StatusCode: 404}}))
+}
+
+func TestGitHubImpl_GetGist_Cancelled(t *testing.T) {
+	ctx, stop := startTestGitHubServer(func(writer http.ResponseWriter, request *http.Request) {
+		_, _ = writer.Write([]byte(`{"id":"aa11"}`))
+	})
+	defer stop()
+	cancelled, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	_, err := ctx.NewGitHub().GetGist(cancelled, "aa11", "default")
+	assert.True(t, errors.Is(err, context.Canceled), err)
+}
@@ -4,6 +4,7 @@ go 1.13
 
 require (
 	github.com/joho/godotenv v1.3.0
+	github.com/sergi/go-diff v1.0.0
 	github.com/stretchr/testify v1.4.0
 	github.com/urfave/cli/v2 v2.1.1
 	gopkg.in/src-d/go-git.v4 v4.13.1
@@ -0,0 +1,92 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+)
+
+// HistoryCommand shows revisions of a gist.
+type HistoryCommand struct {
+	ProfileName
+	// Target is id or name of a gist. Only id is available for a gist which is not cloned.
+	Target string
+	OutputFormat
+	Writer io.Writer
+}
+
+// Run command of HistoryCommand
+func (hc *HistoryCommand) Run(ctx context.Context, pctx ProfileContext) error {
+	if hc.Writer == nil {
+		return errors.New("HistoryCommand_Run: writer is not given")
+	}
+	formatter, err := NewFormatter(hc.OutputFormat)
+	if err != nil {
+		return fmt.Errorf("HistoryCommand_Run_NewFormatter: %w", err)
+	}
+	gistID, err := resolveTarget(pctx, hc.ProfileName, hc.Target)
+	if err != nil {
+		return fmt.Errorf("HistoryCommand_Run_ResolveTarget: %w", err)
+	}
+	gist, err := pctx.NewGitHub().GetGist(ctx, gistID, hc.ProfileName)
+	if err != nil {
+		return fmt.Errorf("HistoryCommand_Run_GetGist: %w", err)
+	}
+	records := make(historyRecords, len(gist.History))
+	for i, revision := range gist.History {
+		records[i] = HistoryEntry{
+			Version:     revision.Version,
+			CommittedAt: revision.CommittedAt,
+			User:        revision.User.Login,
+			Additions:   revision.ChangeStatus.Additions,
+			Deletions:   revision.ChangeStatus.Deletions,
+			Total:       revision.ChangeStatus.Total,
+		}
+	}
+	err = formatter.Format(hc.Writer, records)
+	if err != nil {
+		return fmt.Errorf("HistoryCommand_Run_Format: %w", err)
+	}
+	return nil
+}
+
+// HistoryEntry is a revision of gist to be shown.
+type HistoryEntry struct {
+	Version     string `json:"version" xml:"version" yaml:"version"`
+	CommittedAt string `json:"committed_at" xml:"committed_at" yaml:"committed_at"`
+	User        string `json:"user" xml:"user" yaml:"user"`
+	Additions   int    `json:"additions" xml:"additions" yaml:"additions"`
+	Deletions   int    `json:"deletions" xml:"deletions" yaml:"deletions"`
+	Total       int    `json:"total" xml:"total" yaml:"total"`
+}
+
+type historyRecords []HistoryEntry
+
+func (records historyRecords) Items() interface{} {
+	return []HistoryEntry(records)
+}
+
+func (records historyRecords) XMLNames() (string, string) {
+	return "history", "revision"
+}
+
+func (records historyRecords) Header() []string {
+	return []string{"version", "committed_at", "user", "additions", "deletions", "total"}
+}
+
+func (records historyRecords) Rows() [][]string {
+	rows := make([][]string, len(records))
+	for i, entry := range records {
+		rows[i] = []string{
+			entry.Version,
+			entry.CommittedAt,
+			entry.User,
+			strconv.Itoa(entry.Additions),
+			strconv.Itoa(entry.Deletions),
+			strconv.Itoa(entry.Total),
+		}
+	}
+	return rows
+}
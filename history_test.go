@@ -0,0 +1,29 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"github.com/stretchr/testify/assert"
+	"net/http"
+	"testing"
+)
+
+func TestHistoryCommand_Run(t *testing.T) {
+	ctx, stop := startTestGitHubServer(func(writer http.ResponseWriter, request *http.Request) {
+		assert.Equal(t, "/gists/aa11", request.URL.Path)
+		_, _ = writer.Write([]byte(`{"id":"aa11","history":[
+{"version":"v2","committed_at":"2020-01-02T00:00:00Z","user":{"login":"test-user"},"change_status":{"total":3,"additions":2,"deletions":1}},
+{"version":"v1","committed_at":"2020-01-01T00:00:00Z","user":{"login":"test-user"},"change_status":{"total":1,"additions":1,"deletions":0}}]}`))
+	})
+	defer stop()
+	ctx.CurrentProfiles[0].Dir = "build/test/not-existing"
+
+	buffer := new(bytes.Buffer)
+	command := HistoryCommand{ProfileName: "default", Target: "aa11", OutputFormat: "csv", Writer: buffer}
+	err := command.Run(context.Background(), ctx)
+	assert.Nil(t, err)
+	assert.Equal(t, `version,committed_at,user,additions,deletions,total
+v2,2020-01-02T00:00:00Z,test-user,2,1,3
+v1,2020-01-01T00:00:00Z,test-user,1,0,1
+`, buffer.String())
+}
@@ -0,0 +1,85 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+const (
+	// LayoutFlat places gists directly under destination directory.
+	LayoutFlat Layout = "flat"
+	// LayoutOwner places gists under directories of their owners.
+	LayoutOwner Layout = "owner/id"
+	// LayoutDate places gists under directories of year and month of their creation.
+	LayoutDate Layout = "year/month/id"
+	// LayoutLanguage places gists under directories of the first language of their files.
+	LayoutLanguage Layout = "language/id"
+)
+
+// unknownLayoutDir is a directory for gists whose owner or language is unknown.
+const unknownLayoutDir = "unknown"
+
+// NewLayout validates layout. Empty string is treated as flat.
+func NewLayout(layout string) (Layout, error) {
+	switch Layout(layout) {
+	case "", LayoutFlat:
+		return LayoutFlat, nil
+	case LayoutOwner, LayoutDate, LayoutLanguage:
+		return Layout(layout), nil
+	}
+	return "", fmt.Errorf("unknown layout: %s(available: %s, %s, %s, %s)", layout, LayoutFlat, LayoutOwner, LayoutDate, LayoutLanguage)
+}
+
+// Parent returns directory where the gist is placed, relative to destination directory.
+// For flat layout, empty string will be returned.
+func (layout Layout) Parent(md RepositoryMetadata) string {
+	switch layout {
+	case LayoutOwner:
+		return layoutDirName(md.Owner)
+	case LayoutDate:
+		return time.Unix(md.Created, 0).UTC().Format("2006/01")
+	case LayoutLanguage:
+		languages := md.Languages()
+		if len(languages) == 0 {
+			return unknownLayoutDir
+		}
+		return layoutDirName(strings.ToLower(languages[0]))
+	}
+	return ""
+}
+
+// Path returns path of the gist named name, relative to destination directory.
+func (layout Layout) Path(md RepositoryMetadata, name string) string {
+	parent := layout.Parent(md)
+	if parent == "" {
+		return name
+	}
+	return fmt.Sprintf("%s/%s", parent, name)
+}
+
+// checkLayoutParent fails if the parent directory is the directory of another recorded gist or inside it,
+// so that a gist is not placed in the working tree of another gist.
+func checkLayoutParent(items []RepositoryMetadata, gistID string, parent string) error {
+	if parent == "" {
+		return nil
+	}
+	for _, md := range items {
+		if md.ID == gistID {
+			continue
+		}
+		other := md.RelativePath()
+		if parent == other || strings.HasPrefix(parent, other+"/") {
+			return fmt.Errorf("directory %s is used by gist %s", other, md.ID)
+		}
+	}
+	return nil
+}
+
+func layoutDirName(name string) string {
+	dirName := sanitizeDirName(name)
+	if dirName == "" {
+		return unknownLayoutDir
+	}
+	return dirName
+}
@@ -0,0 +1,54 @@
+package main
+
+import (
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestNewLayout(t *testing.T) {
+	for _, layout := range []string{"", "flat", "owner/id", "year/month/id", "language/id"} {
+		_, err := NewLayout(layout)
+		assert.Nil(t, err, layout)
+	}
+	layout, err := NewLayout("")
+	assert.Nil(t, err)
+	assert.Equal(t, LayoutFlat, layout)
+	_, err = NewLayout("id/owner")
+	assert.NotNil(t, err)
+}
+
+func TestLayout_Path(t *testing.T) {
+	md := RepositoryMetadata{
+		ID:      "aa11",
+		Owner:   "mike-neck",
+		Created: 1580515200,
+		Files: []FileMetadata{
+			{Filename: "README.md"},
+			{Filename: "main.go", Language: "Go"},
+		},
+	}
+	assert.Equal(t, "aa11", LayoutFlat.Path(md, "aa11"))
+	assert.Equal(t, "mike-neck/aa11", LayoutOwner.Path(md, "aa11"))
+	assert.Equal(t, "2020/02/snippet", LayoutDate.Path(md, "snippet"))
+	assert.Equal(t, "go/aa11", LayoutLanguage.Path(md, "aa11"))
+
+	unknown := RepositoryMetadata{ID: "bb22"}
+	assert.Equal(t, "unknown/bb22", LayoutOwner.Path(unknown, "bb22"))
+	assert.Equal(t, "unknown/bb22", LayoutLanguage.Path(unknown, "bb22"))
+}
+
+func TestRepositoryMetadata_RelativePath(t *testing.T) {
+	assert.Equal(t, "aa11", (&RepositoryMetadata{ID: "aa11"}).RelativePath())
+	assert.Equal(t, "first", (&RepositoryMetadata{ID: "aa11", Name: "first"}).RelativePath())
+	assert.Equal(t, "go/first", (&RepositoryMetadata{ID: "aa11", Name: "first", Path: "go/first"}).RelativePath())
+}
+
+func TestCheckLayoutParent(t *testing.T) {
+	items := []RepositoryMetadata{{ID: "aa11", Name: "alice"}, {ID: "bb22", Path: "bob/bb22"}}
+	assert.Nil(t, checkLayoutParent(items, "cc33", ""))
+	assert.Nil(t, checkLayoutParent(items, "cc33", "bob"))
+	assert.Nil(t, checkLayoutParent(items, "aa11", "alice"))
+	assert.NotNil(t, checkLayoutParent(items, "cc33", "alice"))
+	assert.NotNil(t, checkLayoutParent(items, "cc33", "bob/bb22"))
+	assert.NotNil(t, checkLayoutParent(items, "cc33", "bob/bb22/go"))
+}
@@ -0,0 +1,216 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// SortOrder is an order of gists to be listed.
+type SortOrder string
+
+const (
+	pubDesc SortOrder = "pub-desc"
+	pubAsc  SortOrder = "pub-asc"
+	idDesc  SortOrder = "id-desc"
+	idAsc   SortOrder = "id-asc"
+)
+
+// Sort sorts items in the SortOrder.
+func (order SortOrder) Sort(items []RepositoryMetadata) error {
+	var less func(i, j int) bool
+	switch order {
+	case pubDesc:
+		less = func(i, j int) bool { return items[i].Created > items[j].Created }
+	case pubAsc:
+		less = func(i, j int) bool { return items[i].Created < items[j].Created }
+	case idDesc:
+		less = func(i, j int) bool { return items[i].ID > items[j].ID }
+	case idAsc:
+		less = func(i, j int) bool { return items[i].ID < items[j].ID }
+	default:
+		return fmt.Errorf("unknown sort order: %s(available: pub-desc, pub-asc, id-desc, id-asc)", order)
+	}
+	sort.SliceStable(items, less)
+	return nil
+}
+
+// Paging is a limit and a page position of items.
+type Paging struct {
+	Limit int
+	Page  int
+}
+
+// Apply returns items in the page. If Limit is 0, all items will be returned.
+func (p *Paging) Apply(items []RepositoryMetadata) ([]RepositoryMetadata, error) {
+	if p.Limit < 0 {
+		return nil, fmt.Errorf("limit should be 0 or positive number: %d", p.Limit)
+	}
+	if p.Page < 1 {
+		return nil, fmt.Errorf("page should be positive number: %d", p.Page)
+	}
+	if p.Limit == 0 {
+		return items, nil
+	}
+	start := p.Limit * (p.Page - 1)
+	if len(items) <= start {
+		return []RepositoryMetadata{}, nil
+	}
+	end := start + p.Limit
+	if len(items) < end {
+		end = len(items)
+	}
+	return items[start:end], nil
+}
+
+// Visibility is a visibility of gists to be listed.
+type Visibility string
+
+const (
+	visibilityAll    Visibility = "all"
+	visibilityPublic Visibility = "public"
+	visibilitySecret Visibility = "secret"
+)
+
+// Match returns whether the gist has the Visibility. Empty Visibility matches all gists.
+// A gist of unknown visibility matches neither public nor secret.
+func (visibility Visibility) Match(md RepositoryMetadata) (bool, error) {
+	switch visibility {
+	case "", visibilityAll:
+		return true, nil
+	case visibilityPublic:
+		return md.Public != nil && *md.Public, nil
+	case visibilitySecret:
+		return md.Public != nil && !*md.Public, nil
+	}
+	return false, fmt.Errorf("unknown visibility: %s(available: all, public, secret)", visibility)
+}
+
+// ListCommand shows gists cloned under the destination directory of a profile.
+type ListCommand struct {
+	ProfileName
+	OutputFormat
+	Paging
+	SortOrder
+	// StarredOnly shows only starred gists.
+	StarredOnly bool
+	// Language shows only gists containing a file of the language. Case is ignored.
+	Language string
+	Visibility
+	// UpdatedSince shows only gists updated at or after the time. Zero value means no filter.
+	UpdatedSince time.Time
+	Writer       io.Writer
+}
+
+// Run command of ListCommand
+func (lc *ListCommand) Run(ctx context.Context, pctx ProfileContext) error {
+	if lc.Writer == nil {
+		return errors.New("ListCommand_Run: writer is not given")
+	}
+	formatter, err := NewFormatter(lc.OutputFormat)
+	if err != nil {
+		return fmt.Errorf("ListCommand_Run_NewFormatter: %w", err)
+	}
+	destinationDir, err := pctx.Dir(lc.ProfileName)
+	if err != nil {
+		return fmt.Errorf("ListCommand_Run_ProfileContext_Dir: %w", err)
+	}
+	metadataFile, err := destinationDir.Resolve(".gist")
+	if err != nil {
+		return fmt.Errorf("ListCommand_Run_Resolve: %w", err)
+	}
+	metadataIndex, err := LoadMetadataIndex(metadataFile)
+	if err != nil {
+		return fmt.Errorf("ListCommand_Run_LoadMetadataIndex: %w", err)
+	}
+	items, err := metadataIndex.Query(lc.match)
+	if err != nil {
+		return fmt.Errorf("ListCommand_Run_Filter: %w", err)
+	}
+	err = lc.SortOrder.Sort(items)
+	if err != nil {
+		return fmt.Errorf("ListCommand_Run_Sort: %w", err)
+	}
+	page, err := lc.Paging.Apply(items)
+	if err != nil {
+		return fmt.Errorf("ListCommand_Run_Paging: %w", err)
+	}
+	err = formatter.Format(lc.Writer, metadataRecords(page))
+	if err != nil {
+		return fmt.Errorf("ListCommand_Run_Format: %w", err)
+	}
+	return nil
+}
+
+// match tests the gist passes all filters of the command.
+func (lc *ListCommand) match(md RepositoryMetadata) (bool, error) {
+	visible, err := lc.Visibility.Match(md)
+	if err != nil || !visible {
+		return false, err
+	}
+	if lc.StarredOnly && !md.Starred {
+		return false, nil
+	}
+	if lc.Language != "" && !hasLanguage(md, lc.Language) {
+		return false, nil
+	}
+	if !lc.UpdatedSince.IsZero() && md.Updated < lc.UpdatedSince.Unix() {
+		return false, nil
+	}
+	return true, nil
+}
+
+func hasLanguage(md RepositoryMetadata, language string) bool {
+	for _, lang := range md.Languages() {
+		if strings.EqualFold(lang, language) {
+			return true
+		}
+	}
+	return false
+}
+
+type metadataRecords []RepositoryMetadata
+
+func (records metadataRecords) Items() interface{} {
+	return []RepositoryMetadata(records)
+}
+
+func (records metadataRecords) XMLNames() (string, string) {
+	return "gists", "gist"
+}
+
+func (records metadataRecords) Header() []string {
+	return []string{"id", "name", "description", "url", "git_url", "owner", "created", "starred", "fork_of",
+		"html_url", "public", "updated", "comments", "forks", "files", "languages", "path"}
+}
+
+func (records metadataRecords) Rows() [][]string {
+	rows := make([][]string, len(records))
+	for i, md := range records {
+		files := make([]string, len(md.Files))
+		for j, file := range md.Files {
+			files[j] = file.Filename
+		}
+		rows[i] = []string{
+			md.ID, md.Name, md.Description, md.URL, md.GitURL, md.Owner, strconv.FormatInt(md.Created, 10),
+			strconv.FormatBool(md.Starred), md.ForkOf,
+			md.HTMLURL, formatPublic(md.Public), strconv.FormatInt(md.Updated, 10),
+			strconv.Itoa(md.Comments), strconv.Itoa(md.Forks),
+			strings.Join(files, " "), strings.Join(md.Languages(), " "), md.RelativePath(),
+		}
+	}
+	return rows
+}
+
+// formatPublic formats visibility of the gist. Unknown visibility is empty.
+func formatPublic(public *bool) string {
+	if public == nil {
+		return ""
+	}
+	return strconv.FormatBool(*public)
+}
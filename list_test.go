@@ -0,0 +1,168 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"github.com/stretchr/testify/assert"
+	"os"
+	"testing"
+	"time"
+)
+
+func listTestItems() []RepositoryMetadata {
+	return []RepositoryMetadata{
+		{ID: "bb22", Created: 200},
+		{ID: "aa11", Created: 300},
+		{ID: "cc33", Created: 100},
+	}
+}
+
+func idsOf(items []RepositoryMetadata) []string {
+	ids := make([]string, len(items))
+	for i, item := range items {
+		ids[i] = item.ID
+	}
+	return ids
+}
+
+// filterItems queries items with filters of the command.
+func filterItems(command ListCommand, items []RepositoryMetadata) ([]RepositoryMetadata, error) {
+	index := MetadataIndex{items: items}
+	return index.Query(command.match)
+}
+
+func TestSortOrder_Sort(t *testing.T) {
+	expectations := map[SortOrder][]string{
+		pubDesc: {"aa11", "bb22", "cc33"},
+		pubAsc:  {"cc33", "bb22", "aa11"},
+		idDesc:  {"cc33", "bb22", "aa11"},
+		idAsc:   {"aa11", "bb22", "cc33"},
+	}
+	for order, expected := range expectations {
+		items := listTestItems()
+		err := order.Sort(items)
+		assert.Nil(t, err)
+		assert.Equal(t, expected, idsOf(items), string(order))
+	}
+}
+
+func TestSortOrder_Sort_UnknownOrder(t *testing.T) {
+	err := SortOrder("name-asc").Sort(listTestItems())
+	assert.NotNil(t, err)
+}
+
+func TestPaging_Apply(t *testing.T) {
+	paging := Paging{Limit: 2, Page: 2}
+	items, err := paging.Apply(listTestItems())
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"cc33"}, idsOf(items))
+}
+
+func TestPaging_Apply_NoLimit(t *testing.T) {
+	paging := Paging{Limit: 0, Page: 3}
+	items, err := paging.Apply(listTestItems())
+	assert.Nil(t, err)
+	assert.Equal(t, 3, len(items))
+}
+
+func TestPaging_Apply_PageOutOfRange(t *testing.T) {
+	paging := Paging{Limit: 2, Page: 3}
+	items, err := paging.Apply(listTestItems())
+	assert.Nil(t, err)
+	assert.Equal(t, 0, len(items))
+}
+
+func TestPaging_Apply_InvalidPage(t *testing.T) {
+	paging := Paging{Limit: 2, Page: 0}
+	_, err := paging.Apply(listTestItems())
+	assert.NotNil(t, err)
+}
+
+func TestListCommand_Run(t *testing.T) {
+	err := os.MkdirAll("build/test/list", 0755)
+	if err != nil {
+		assert.Fail(t, "unexpected error@mkdir", err)
+		return
+	}
+	_ = os.Remove("build/test/list/.gist")
+	saveTestMetadata(t, "build/test/list/.gist", listTestItems()...)
+	buffer := new(bytes.Buffer)
+	command := ListCommand{
+		ProfileName:  "default",
+		OutputFormat: "csv",
+		Paging:       Paging{Limit: 2, Page: 1},
+		SortOrder:    idAsc,
+		Writer:       buffer,
+	}
+	ctx := ProfileContext{
+		CurrentProfiles: []Profile{{Name: "default", Dir: "build/test/list"}},
+	}
+	err = command.Run(context.Background(), ctx)
+	assert.Nil(t, err)
+	assert.Equal(t, `id,name,description,url,git_url,owner,created,starred,fork_of,html_url,public,updated,comments,forks,files,languages,path
+aa11,,,,,,300,false,,,,0,0,0,,,aa11
+bb22,,,,,,200,false,,,,0,0,0,,,bb22
+`, buffer.String())
+}
+
+func TestListCommand_Filter_Starred(t *testing.T) {
+	items := listTestItems()
+	items[1].Starred = true
+	command := ListCommand{StarredOnly: true}
+	filtered, err := filterItems(command, items)
+	assert.Nil(t, err)
+	assert.Equal(t, []string{items[1].ID}, idsOf(filtered))
+	command = ListCommand{}
+	filtered, err = filterItems(command, items)
+	assert.Nil(t, err)
+	assert.Equal(t, len(items), len(filtered))
+}
+
+func TestListCommand_Filter_LanguageVisibilityUpdated(t *testing.T) {
+	public, secret := true, false
+	items := []RepositoryMetadata{
+		{ID: "aa11", Public: &public, Updated: 1000, Files: []FileMetadata{{Filename: "main.go", Language: "Go"}}},
+		{ID: "bb22", Public: &secret, Updated: 2000, Files: []FileMetadata{{Filename: "main.go", Language: "Go"}}},
+		{ID: "cc33", Public: &public, Updated: 3000, Files: []FileMetadata{{Filename: "README.md", Language: "Markdown"}}},
+		// recorded before visibility is recorded
+		{ID: "dd44", Updated: 4000},
+	}
+	expectations := []struct {
+		command  ListCommand
+		expected []string
+	}{
+		{ListCommand{Language: "go"}, []string{"aa11", "bb22"}},
+		{ListCommand{Visibility: visibilityPublic}, []string{"aa11", "cc33"}},
+		{ListCommand{Visibility: visibilitySecret}, []string{"bb22"}},
+		{ListCommand{Visibility: visibilityAll, UpdatedSince: time.Unix(2000, 0)}, []string{"bb22", "cc33", "dd44"}},
+		{ListCommand{Language: "Go", Visibility: visibilityPublic}, []string{"aa11"}},
+	}
+	for _, expectation := range expectations {
+		filtered, err := filterItems(expectation.command, items)
+		assert.Nil(t, err)
+		assert.Equal(t, expectation.expected, idsOf(filtered))
+	}
+}
+
+func TestListCommand_Filter_UnknownVisibility(t *testing.T) {
+	command := ListCommand{Visibility: "private"}
+	_, err := filterItems(command, listTestItems())
+	assert.NotNil(t, err)
+}
+
+func TestListCommand_Run_NoMetadataFile(t *testing.T) {
+	buffer := new(bytes.Buffer)
+	command := ListCommand{
+		ProfileName:  "default",
+		OutputFormat: "json",
+		Paging:       Paging{Limit: 20, Page: 1},
+		SortOrder:    pubDesc,
+		Writer:       buffer,
+	}
+	ctx := ProfileContext{
+		CurrentProfiles: []Profile{{Name: "default", Dir: "build/test/not-existing"}},
+	}
+	err := command.Run(context.Background(), ctx)
+	assert.Nil(t, err)
+	assert.Equal(t, "[]\n", buffer.String())
+}
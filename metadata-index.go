@@ -0,0 +1,198 @@
+package main
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+)
+
+// metadataIndexVersion is the version of schema of metadata index file.
+// Version 1 is JSON lines of RepositoryMetadata without header, which is migrated on load.
+const metadataIndexVersion = 2
+
+// maxMetadataLineSize is the max size of a line in metadata index file, which grows with files of the gist.
+const maxMetadataLineSize = 4 * 1024 * 1024
+
+// metadataIndexHeader is the first line of metadata index file.
+type metadataIndexHeader struct {
+	Version int `json:"version"`
+}
+
+// MetadataIndex is the index of cloned gists, stored in `.gist` file under destination directory.
+// The file consists of a header line with the schema version, followed by a line of RepositoryMetadata for each gist.
+// Changes are written into the file only by Save.
+type MetadataIndex struct {
+	path  string
+	items []RepositoryMetadata
+	// changes are applied again to the latest file on Save, so that changes by other processes are not lost.
+	changes []metadataChange
+}
+
+// metadataChange is a change of the index made by Upsert or Delete.
+type metadataChange struct {
+	metadata RepositoryMetadata
+	deleted  bool
+}
+
+// LoadMetadataIndex loads MetadataIndex from file. If file is not existing, empty index will be returned.
+// A file of older schema is migrated on load, and written in the current schema by Save.
+// Duplicated entries of a gist are merged into the last one.
+func LoadMetadataIndex(path string) (*MetadataIndex, error) {
+	index := &MetadataIndex{path: path, items: []RepositoryMetadata{}}
+	file, err := os.Open(path)
+	if os.IsNotExist(err) {
+		return index, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("LoadMetadataIndex_Open: %w", err)
+	}
+	defer func() { _ = file.Close() }()
+
+	version := 0
+	scanner := bufio.NewScanner(file)
+	scanner.Buffer(make([]byte, 64*1024), maxMetadataLineSize)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" {
+			continue
+		}
+		if version == 0 {
+			var header metadataIndexHeader
+			err = json.Unmarshal([]byte(line), &header)
+			if err != nil {
+				return nil, fmt.Errorf("LoadMetadataIndex_UnmarshalHeader: %w", err)
+			}
+			if header.Version > metadataIndexVersion {
+				return nil, fmt.Errorf("%s is written in version %d of schema, which is newer than supported version %d", path, header.Version, metadataIndexVersion)
+			}
+			if header.Version > 0 {
+				version = header.Version
+				continue
+			}
+			// a file without header is JSON lines of version 1
+			version = 1
+		}
+		var md RepositoryMetadata
+		err = json.Unmarshal([]byte(line), &md)
+		if err != nil {
+			return nil, fmt.Errorf("LoadMetadataIndex_UnmarshalJson: %w", err)
+		}
+		index.put(md)
+	}
+	err = scanner.Err()
+	if err != nil {
+		return nil, fmt.Errorf("LoadMetadataIndex_Scan: %w", err)
+	}
+	return index, nil
+}
+
+// Items returns a copy of all RepositoryMetadata in the index.
+func (index *MetadataIndex) Items() []RepositoryMetadata {
+	items := make([]RepositoryMetadata, len(index.items))
+	copy(items, index.items)
+	return items
+}
+
+// Find returns RepositoryMetadata whose id or name is equal to idOrName, in the same way as FindMetadata.
+func (index *MetadataIndex) Find(idOrName string) (*RepositoryMetadata, bool) {
+	i := FindMetadata(index.items, idOrName)
+	if i < 0 {
+		return nil, false
+	}
+	md := index.items[i]
+	return &md, true
+}
+
+// Query returns RepositoryMetadata matching the predicate, in order of the index.
+func (index *MetadataIndex) Query(predicate func(md RepositoryMetadata) (bool, error)) ([]RepositoryMetadata, error) {
+	matched := make([]RepositoryMetadata, 0, len(index.items))
+	for _, md := range index.items {
+		ok, err := predicate(md)
+		if err != nil {
+			return nil, err
+		}
+		if ok {
+			matched = append(matched, md)
+		}
+	}
+	return matched, nil
+}
+
+// Upsert replaces RepositoryMetadata of the same id, or adds it to the index if not found.
+func (index *MetadataIndex) Upsert(md RepositoryMetadata) {
+	index.put(md)
+	index.changes = append(index.changes, metadataChange{metadata: md})
+}
+
+// Delete removes RepositoryMetadata of the id, and returns whether it was found.
+func (index *MetadataIndex) Delete(id string) bool {
+	index.changes = append(index.changes, metadataChange{metadata: RepositoryMetadata{ID: id}, deleted: true})
+	return index.remove(id)
+}
+
+func (index *MetadataIndex) put(md RepositoryMetadata) {
+	for i, item := range index.items {
+		if item.ID == md.ID {
+			index.items[i] = md
+			return
+		}
+	}
+	index.items = append(index.items, md)
+}
+
+func (index *MetadataIndex) remove(id string) bool {
+	for i, item := range index.items {
+		if item.ID == id {
+			index.items = append(index.items[:i], index.items[i+1:]...)
+			return true
+		}
+	}
+	return false
+}
+
+// Save applies changes to the latest index file and writes it in the current schema, while holding lock of the file.
+// The file is replaced atomically, so that it is not left partially written.
+func (index *MetadataIndex) Save() error {
+	lock, err := lockFile(index.path)
+	if err != nil {
+		return fmt.Errorf("MetadataIndex_Save_Lock: %w", err)
+	}
+	defer func() { _ = lock.Unlock() }()
+
+	latest, err := LoadMetadataIndex(index.path)
+	if err != nil {
+		return fmt.Errorf("MetadataIndex_Save_Reload: %w", err)
+	}
+	for _, change := range index.changes {
+		if change.deleted {
+			latest.remove(change.metadata.ID)
+		} else {
+			latest.put(change.metadata)
+		}
+	}
+	err = writeFileAtomically(index.path, latest.writeTo)
+	if err != nil {
+		return fmt.Errorf("MetadataIndex_Save: %w", err)
+	}
+	index.items = latest.items
+	index.changes = nil
+	return nil
+}
+
+func (index *MetadataIndex) writeTo(writer io.Writer) error {
+	encoder := json.NewEncoder(writer)
+	err := encoder.Encode(metadataIndexHeader{Version: metadataIndexVersion})
+	if err != nil {
+		return err
+	}
+	for _, md := range index.items {
+		err = encoder.Encode(md)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
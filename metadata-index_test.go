@@ -0,0 +1,126 @@
+package main
+
+import (
+	"github.com/stretchr/testify/assert"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func prepareMetadataIndexTestDir(t *testing.T) string {
+	dir, err := ioutil.TempDir("", "gist-index")
+	if err != nil {
+		assert.Fail(t, "unexpected error@TempDir", err)
+	}
+	return dir
+}
+
+// saveTestMetadata records items into metadata index file as a fixture.
+func saveTestMetadata(t *testing.T, path string, items ...RepositoryMetadata) {
+	index, err := LoadMetadataIndex(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, md := range items {
+		index.Upsert(md)
+	}
+	err = index.Save()
+	if err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestLoadMetadataIndex_MigrateJSONLines(t *testing.T) {
+	dir := prepareMetadataIndexTestDir(t)
+	defer func() { _ = os.RemoveAll(dir) }()
+	file := filepath.Join(dir, ".gist")
+	legacy := `{"id":"aa11","name":"first","url":"","git_url":"","owner":"test-user","created":100}
+{"id":"bb22","url":"","git_url":"","owner":"test-user","created":200}
+
+{"id":"aa11","name":"again","url":"","git_url":"","owner":"test-user","created":300}
+`
+	assert.Nil(t, ioutil.WriteFile(file, []byte(legacy), 0644))
+
+	index, err := LoadMetadataIndex(file)
+	assert.Nil(t, err)
+	items := index.Items()
+	assert.Equal(t, []string{"aa11", "bb22"}, idsOf(items))
+	assert.Equal(t, "again", items[0].Name)
+
+	assert.Nil(t, index.Save())
+	saved, err := readExistingMetadataFile(file)
+	assert.Nil(t, err)
+	assert.Equal(t, items, saved)
+	reloaded, err := LoadMetadataIndex(file)
+	assert.Nil(t, err)
+	assert.Equal(t, items, reloaded.Items())
+}
+
+func TestLoadMetadataIndex_NewerVersion(t *testing.T) {
+	dir := prepareMetadataIndexTestDir(t)
+	defer func() { _ = os.RemoveAll(dir) }()
+	file := filepath.Join(dir, ".gist")
+	assert.Nil(t, ioutil.WriteFile(file, []byte("{\"version\":3}\n{\"id\":\"aa11\"}\n"), 0644))
+
+	_, err := LoadMetadataIndex(file)
+	assert.NotNil(t, err)
+}
+
+func TestMetadataIndex_UpsertDeleteQuery(t *testing.T) {
+	index := MetadataIndex{}
+	index.Upsert(RepositoryMetadata{ID: "aa11", Name: "first"})
+	index.Upsert(RepositoryMetadata{ID: "bb22", Starred: true})
+	index.Upsert(RepositoryMetadata{ID: "aa11", Name: "renamed"})
+	assert.Equal(t, []string{"aa11", "bb22"}, idsOf(index.Items()))
+
+	md, found := index.Find("renamed")
+	assert.True(t, found)
+	assert.Equal(t, "aa11", md.ID)
+	_, found = index.Find("first")
+	assert.False(t, found)
+
+	starred, err := index.Query(func(md RepositoryMetadata) (bool, error) { return md.Starred, nil })
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"bb22"}, idsOf(starred))
+
+	assert.True(t, index.Delete("aa11"))
+	assert.False(t, index.Delete("aa11"))
+	assert.Equal(t, []string{"bb22"}, idsOf(index.Items()))
+}
+
+func TestMetadataIndex_Save_Atomic(t *testing.T) {
+	dir := prepareMetadataIndexTestDir(t)
+	defer func() { _ = os.RemoveAll(dir) }()
+	file := filepath.Join(dir, ".gist")
+
+	index, err := LoadMetadataIndex(file)
+	assert.Nil(t, err)
+	index.Upsert(RepositoryMetadata{ID: "aa11"})
+	assert.Nil(t, index.Save())
+
+	entries, err := ioutil.ReadDir(dir)
+	assert.Nil(t, err)
+	assert.Equal(t, 1, len(entries))
+	assert.Equal(t, ".gist", entries[0].Name())
+	assert.Equal(t, os.FileMode(0644), entries[0].Mode().Perm())
+}
+
+func TestClonedGist_Record_Reclone(t *testing.T) {
+	dir := prepareMetadataIndexTestDir(t)
+	defer func() { _ = os.RemoveAll(dir) }()
+	file := filepath.Join(dir, ".gist")
+
+	for _, name := range []string{"first", "second"} {
+		cloned := ClonedGist{
+			Directory:    filepath.Join(dir, name),
+			MetadataFile: file,
+			Metadata:     RepositoryMetadata{ID: "aa11", Name: name},
+		}
+		assert.Nil(t, cloned.Record())
+	}
+	items, err := LoadMetadataFrom(file)
+	assert.Nil(t, err)
+	assert.Equal(t, 1, len(items))
+	assert.Equal(t, "second", items[0].Name)
+}
@@ -0,0 +1,158 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+	"text/template"
+	"time"
+	"unicode"
+)
+
+// NameTemplateData is values available in NameTemplate.
+type NameTemplateData struct {
+	ID          string
+	Owner       string
+	Description string
+	// Slug is the description in lower case words joined by `-`.
+	Slug string
+	// FirstFile is the first file name of the gist in alphabetical order.
+	FirstFile string
+	Created   time.Time
+}
+
+// NewNameTemplateData creates NameTemplateData from gist.
+func NewNameTemplateData(gist Gist) NameTemplateData {
+	fileNames := make([]string, 0, len(gist.Files))
+	for name := range gist.Files {
+		fileNames = append(fileNames, name)
+	}
+	sort.Strings(fileNames)
+	firstFile := ""
+	if len(fileNames) > 0 {
+		firstFile = fileNames[0]
+	}
+	created, _ := time.Parse("2006-01-02T15:04:05Z", gist.CreatedAt)
+	return NameTemplateData{
+		ID:          gist.ID,
+		Owner:       gist.Owner.Login,
+		Description: gist.Description,
+		Slug:        slugify(gist.Description),
+		FirstFile:   firstFile,
+		Created:     created,
+	}
+}
+
+// Parse parses NameTemplate. Function `slug` is available in addition to builtin functions.
+func (nt NameTemplate) Parse() (*template.Template, error) {
+	tmpl, err := template.New("name_template").
+		Funcs(template.FuncMap{"slug": slugify}).
+		Parse(string(nt))
+	if err != nil {
+		return nil, fmt.Errorf("invalid name_template: %w", err)
+	}
+	return tmpl, nil
+}
+
+// Render executes NameTemplate with the gist, and returns a name usable as directory name.
+// If the result is empty, id of the gist will be returned.
+func (nt NameTemplate) Render(gist Gist) (string, error) {
+	tmpl, err := nt.Parse()
+	if err != nil {
+		return "", err
+	}
+	var buffer bytes.Buffer
+	err = tmpl.Execute(&buffer, NewNameTemplateData(gist))
+	if err != nil {
+		return "", fmt.Errorf("NameTemplate_Render_Execute: %w", err)
+	}
+	name := sanitizeDirName(buffer.String())
+	if name == "" {
+		return gist.ID, nil
+	}
+	return name, nil
+}
+
+// maxSlugLength is the max length of slug in runes.
+const maxSlugLength = 50
+
+// slugify converts text into lower case words joined by `-`.
+func slugify(text string) string {
+	words := strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+	slug := []rune(strings.Join(words, "-"))
+	if len(slug) > maxSlugLength {
+		slug = slug[:maxSlugLength]
+	}
+	return strings.Trim(string(slug), "-")
+}
+
+// sanitizeDirName replaces characters not allowed in a directory name with `-`.
+func sanitizeDirName(name string) string {
+	sanitized := strings.Map(func(r rune) rune {
+		if unicode.IsControl(r) || strings.ContainsRune(`/\:*?"<>|`, r) {
+			return '-'
+		}
+		return r
+	}, strings.TrimSpace(name))
+	// hidden names are reserved for metadata file
+	return strings.TrimLeft(sanitized, ".")
+}
+
+// maxNameCollisions is the max number of suffixes tried for a colliding name.
+const maxNameCollisions = 100
+
+// reserveDirectory creates a directory for the name under parent directory, which is relative to destination directory.
+// If the name is used by another recorded gist or an existing directory, suffix `-2`, `-3`... is added.
+// It returns the reserved name, and whether the directory is created.
+// The directory recorded for the gist of gistID is not created, so that the gist is cloned into it again.
+func reserveDirectory(destinationDir DestinationDir, parent string, name string, gistID string, items []RepositoryMetadata) (string, bool, error) {
+	used := make(map[string]bool, len(items))
+	own := ""
+	for _, md := range items {
+		if md.ID == gistID {
+			own = md.RelativePath()
+			continue
+		}
+		used[md.RelativePath()] = true
+		// names are unique, so that a gist can be given by its name
+		used[md.DirName()] = true
+	}
+	for i := 1; i <= maxNameCollisions; i++ {
+		candidate := name
+		if i > 1 {
+			candidate = fmt.Sprintf("%s-%d", name, i)
+		}
+		path := candidate
+		if parent != "" {
+			path = fmt.Sprintf("%s/%s", parent, candidate)
+		}
+		if path == own {
+			return candidate, false, nil
+		}
+		if used[candidate] || used[path] {
+			continue
+		}
+		directory, err := destinationDir.Resolve(path)
+		if err != nil {
+			return "", false, fmt.Errorf("ReserveDirectory_Resolve: %w", err)
+		}
+		err = createParentDirectory(directory)
+		if err != nil {
+			return "", false, fmt.Errorf("ReserveDirectory_CreateParentDir(%s): %w", directory, err)
+		}
+		// Mkdir fails if the directory exists, so that concurrent clones do not share a directory.
+		err = os.Mkdir(directory, 0755)
+		if os.IsExist(err) {
+			continue
+		}
+		if err != nil {
+			return "", false, fmt.Errorf("ReserveDirectory_Mkdir(%s): %w", directory, err)
+		}
+		return candidate, true, nil
+	}
+	return "", false, fmt.Errorf("too many gists named %s", name)
+}
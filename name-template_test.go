@@ -0,0 +1,128 @@
+package main
+
+import (
+	"context"
+	"github.com/stretchr/testify/assert"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"strings"
+	"testing"
+)
+
+var nameTemplateTestGist = Gist{
+	ID:          "aa11",
+	Description: "Example of HTTP server, in Go!",
+	CreatedAt:   "2020-01-02T03:04:05Z",
+	Owner:       GitHubUser{Login: "mike-neck"},
+	Files: map[string]GistFile{
+		"server.go": {Filename: "server.go"},
+		"main.go":   {Filename: "main.go"},
+	},
+}
+
+func TestNameTemplate_Render(t *testing.T) {
+	templates := map[NameTemplate]string{
+		"{{.Owner}}-{{.Slug}}":                               "mike-neck-example-of-http-server-in-go",
+		`{{.Created.Format "2006-01-02"}}_{{.ID}}`:           "2020-01-02_aa11",
+		"{{.FirstFile}}":                                     "main.go",
+		"{{slug .FirstFile}}":                                "main-go",
+		"{{.Owner}}/{{.Description}}":                        "mike-neck-Example of HTTP server, in Go!",
+		"{{if .Description}}{{.Slug}}{{else}}{{.ID}}{{end}}": "example-of-http-server-in-go",
+		"  ":        "aa11",
+		"..{{.ID}}": "aa11",
+	}
+	for nameTemplate, expected := range templates {
+		name, err := nameTemplate.Render(nameTemplateTestGist)
+		assert.Nil(t, err, nameTemplate)
+		assert.Equal(t, expected, name, nameTemplate)
+	}
+}
+
+func TestNameTemplate_Render_Invalid(t *testing.T) {
+	_, err := NameTemplate("{{.Owner").Render(nameTemplateTestGist)
+	assert.NotNil(t, err)
+	_, err = NameTemplate("{{.Unknown}}").Render(nameTemplateTestGist)
+	assert.NotNil(t, err)
+}
+
+func TestSlugify(t *testing.T) {
+	assert.Equal(t, "", slugify(""))
+	assert.Equal(t, "日本語-gist", slugify("  日本語 Gist!! "))
+	assert.Equal(t, maxSlugLength, len(slugify(strings.Repeat("abc ", 20))))
+}
+
+func TestReserveDirectory_Collision(t *testing.T) {
+	parent, err := ioutil.TempDir("", "gist-name-")
+	assert.Nil(t, err)
+	defer func() { _ = os.RemoveAll(parent) }()
+	// "snippet" is recorded, and "snippet-2" exists without record.
+	items := []RepositoryMetadata{{ID: "aa11", Name: "snippet"}}
+	assert.Nil(t, os.Mkdir(parent+"/snippet-2", 0755))
+
+	name, created, err := reserveDirectory(DestinationDir(parent), "", "snippet", "bb22", items)
+	assert.Nil(t, err)
+	assert.True(t, created)
+	assert.Equal(t, "snippet-3", name)
+	result, err := testDestinationDir(parent + "/snippet-3")
+	assert.Nil(t, err)
+	assert.Equal(t, resultEmptyDir, result)
+
+	name, _, err = reserveDirectory(DestinationDir(parent), "", "snippet", "cc33", items)
+	assert.Nil(t, err)
+	assert.Equal(t, "snippet-4", name)
+}
+
+func TestReserveDirectory_Reclone(t *testing.T) {
+	parent, err := ioutil.TempDir("", "gist-name-")
+	assert.Nil(t, err)
+	defer func() { _ = os.RemoveAll(parent) }()
+	// "snippet" is recorded for aa11 and its directory exists.
+	items := []RepositoryMetadata{{ID: "aa11", Name: "snippet"}}
+	assert.Nil(t, os.Mkdir(parent+"/snippet", 0755))
+
+	name, created, err := reserveDirectory(DestinationDir(parent), "", "snippet", "aa11", items)
+	assert.Nil(t, err)
+	assert.False(t, created)
+	assert.Equal(t, "snippet", name)
+	_, err = os.Stat(parent + "/snippet-2")
+	assert.True(t, os.IsNotExist(err))
+
+	name, created, err = reserveDirectory(DestinationDir(parent), "", "snippet", "bb22", items)
+	assert.Nil(t, err)
+	assert.True(t, created)
+	assert.Equal(t, "snippet-2", name)
+}
+
+func TestReserveDirectory_Parent(t *testing.T) {
+	parent, err := ioutil.TempDir("", "gist-name-")
+	assert.Nil(t, err)
+	defer func() { _ = os.RemoveAll(parent) }()
+	items := []RepositoryMetadata{{ID: "aa11", Name: "snippet", Path: "mike-neck/snippet"}}
+
+	name, _, err := reserveDirectory(DestinationDir(parent), "other-user", "snippet", "bb22", items)
+	assert.Nil(t, err)
+	assert.Equal(t, "snippet-2", name)
+	_, err = os.Stat(parent + "/other-user/snippet-2")
+	assert.Nil(t, err)
+}
+
+func TestCloneCommand_CloneRepository_TemplateNameRemovedOnFailure(t *testing.T) {
+	ctx, stop := startTestGitHubServer(func(writer http.ResponseWriter, request *http.Request) {
+		_, _ = writer.Write([]byte(`{"id":"aa11","created_at":"2020-01-02T03:04:05Z","description":"my snippet","owner":{"login":"mike-neck"}}`))
+	})
+	defer stop()
+	parent, err := ioutil.TempDir("", "gist-name-")
+	assert.Nil(t, err)
+	defer func() { _ = os.RemoveAll(parent) }()
+	ctx.CurrentProfiles[0].Dir = DestinationDir(parent)
+	ctx.CurrentProfiles[0].NameTemplate = "{{.Slug}}"
+	// nothing listens on the port, then git clone fails.
+	command := CloneCommand{GistID: "aa11", ProfileName: "default", GistHost: "127.0.0.1:1"}
+
+	_, err = command.CloneRepository(context.Background(), ctx)
+	assert.NotNil(t, err)
+	assert.Equal(t, RepositoryName("my-snippet"), command.RepositoryName)
+	_, err = os.Stat(parent + "/my-snippet")
+	assert.True(t, os.IsNotExist(err))
+}
@@ -1,17 +1,28 @@
 package main
 
 import (
+	"context"
 	"fmt"
 	"gopkg.in/yaml.v2"
 	"io"
+	"time"
 )
 
 // NewProfileCommand returns Command for command `profile`.
-func NewProfileCommand(name, token, dir *string) Command {
+// isSet tells whether a flag is given, so that only given flags override the existing profile.
+func NewProfileCommand(name, token, dir, sshKeyFile, apiBaseURL, gistHost, timeout *string, retries *int, nameTemplate, layout *string, isSet func(flag string) bool) Command {
 	return &AppendOrOverrideProfilesCommand{
 		ProfileName:       ProfileName(*name),
 		GitHubAccessToken: GitHubAccessToken(*token),
 		DestinationDir:    DestinationDir(*dir),
+		SSHKeyFile:        SSHKeyFile(*sshKeyFile),
+		APIBaseURL:        APIBaseURL(*apiBaseURL),
+		GistHost:          GistHost(*gistHost),
+		RequestTimeout:    RequestTimeout(*timeout),
+		RetryCount:        RetryCount(*retries),
+		NameTemplate:      NameTemplate(*nameTemplate),
+		Layout:            Layout(*layout),
+		IsSet:             isSet,
 	}
 }
 
@@ -20,45 +31,82 @@ type AppendOrOverrideProfilesCommand struct {
 	ProfileName
 	GitHubAccessToken
 	DestinationDir
+	SSHKeyFile
+	APIBaseURL
+	GistHost
+	RequestTimeout
+	RetryCount
+	NameTemplate
+	Layout
+	// IsSet tells whether the flag of a field is given. If nil, all fields override the existing profile.
+	IsSet func(flag string) bool
 }
 
 // Run profile command.
-func (command *AppendOrOverrideProfilesCommand) Run(ctx ProfileContext) error {
-	executor := command.executor(ctx)
-	profileLists := executor.Invoke(ctx.CurrentProfiles)
-
-	writeCloser, err := ctx.ProfileFile.NewWriter()
-	if err != nil {
-		return fmt.Errorf("AppendOrOverrideProfilesCommand_Run_NewWriter: %w", err)
+func (command *AppendOrOverrideProfilesCommand) Run(_ context.Context, pctx ProfileContext) error {
+	if command.NameTemplate != "" {
+		_, err := command.NameTemplate.Parse()
+		if err != nil {
+			return fmt.Errorf("AppendOrOverrideProfilesCommand_Run_NameTemplate: %w", err)
+		}
 	}
-	defer func() { _ = writeCloser.Close() }()
-
-	err = profileLists.saveTo(writeCloser)
+	if command.Layout != "" {
+		_, err := NewLayout(string(command.Layout))
+		if err != nil {
+			return fmt.Errorf("AppendOrOverrideProfilesCommand_Run_Layout: %w", err)
+		}
+	}
+	if command.RequestTimeout != "" {
+		timeout, err := time.ParseDuration(string(command.RequestTimeout))
+		if err != nil {
+			return fmt.Errorf("AppendOrOverrideProfilesCommand_Run_Timeout: %w", err)
+		}
+		if timeout <= 0 {
+			return fmt.Errorf("timeout should be positive: %s", command.RequestTimeout)
+		}
+	}
+	if command.RetryCount < 0 {
+		return fmt.Errorf("retries should not be negative: %d", command.RetryCount)
+	}
+	// profiles are read again under lock, so that profiles changed by another process are not lost
+	err := pctx.ProfileFile.Update(func(profiles []Profile) []Profile {
+		latest := pctx
+		latest.CurrentProfiles = profiles
+		executor := command.executor(latest)
+		return executor.Invoke(profiles)
+	})
 	if err != nil {
-		return fmt.Errorf("AppendOrOverrideProfilesCommand_Run_SaveTo: %w", err)
+		return fmt.Errorf("AppendOrOverrideProfilesCommand_Run_Update: %w", err)
 	}
 	return nil
 }
 
 ////////
 // determine profileCommandExecutor
-func (command *AppendOrOverrideProfilesCommand) executor(ctx ProfileContext) profileCommandExecutor {
+func (command *AppendOrOverrideProfilesCommand) executor(pctx ProfileContext) profileCommandExecutor {
 	profileName := command.ProfileName
-	for _, p := range ctx.CurrentProfiles {
+	for _, p := range pctx.CurrentProfiles {
 		if p.Name == profileName {
-			executor := overrideExecutor{Profile{
-				Name:  profileName,
-				Token: command.GitHubAccessToken,
-				Dir:   command.DestinationDir,
-			}}
+			executor := overrideExecutor{Profile: command.profile(), isSet: command.IsSet}
 			return &executor
 		}
 	}
-	return &appendExecutor{Profile{
-		Name:  profileName,
-		Token: command.GitHubAccessToken,
-		Dir:   command.DestinationDir,
-	}}
+	return &appendExecutor{command.profile()}
+}
+
+func (command *AppendOrOverrideProfilesCommand) profile() Profile {
+	return Profile{
+		Name:         command.ProfileName,
+		Token:        command.GitHubAccessToken,
+		Dir:          command.DestinationDir,
+		SSHKeyFile:   command.SSHKeyFile,
+		APIBaseURL:   command.APIBaseURL,
+		GistHost:     command.GistHost,
+		Timeout:      command.RequestTimeout,
+		Retries:      command.RetryCount,
+		NameTemplate: command.NameTemplate,
+		Layout:       command.Layout,
+	}
 }
 
 ////////
@@ -84,6 +132,7 @@ func (ae *appendExecutor) Invoke(currentProfiles []Profile) profileList {
 
 type overrideExecutor struct {
 	Profile
+	isSet func(flag string) bool
 }
 
 func (oe *overrideExecutor) profileName() ProfileName {
@@ -94,7 +143,7 @@ func (oe *overrideExecutor) Invoke(currentProfiles []Profile) profileList {
 	profiles := make([]Profile, len(currentProfiles))
 	for i, p := range currentProfiles {
 		if p.Name == oe.profileName() {
-			profiles[i] = oe.Profile
+			profiles[i] = oe.merge(p)
 		} else {
 			profiles[i] = p
 		}
@@ -102,6 +151,42 @@ func (oe *overrideExecutor) Invoke(currentProfiles []Profile) profileList {
 	return profiles
 }
 
+// merge overrides fields of the current profile with the given flags, and keeps the others.
+func (oe *overrideExecutor) merge(current Profile) Profile {
+	if oe.isSet == nil {
+		return oe.Profile
+	}
+	profile := current
+	if oe.isSet("token") {
+		profile.Token = oe.Token
+	}
+	if oe.isSet("dir") {
+		profile.Dir = oe.Dir
+	}
+	if oe.isSet("ssh-key") {
+		profile.SSHKeyFile = oe.SSHKeyFile
+	}
+	if oe.isSet("api-base-url") {
+		profile.APIBaseURL = oe.APIBaseURL
+	}
+	if oe.isSet("gist-host") {
+		profile.GistHost = oe.GistHost
+	}
+	if oe.isSet("timeout") {
+		profile.Timeout = oe.Timeout
+	}
+	if oe.isSet("retries") {
+		profile.Retries = oe.Retries
+	}
+	if oe.isSet("name-template") {
+		profile.NameTemplate = oe.NameTemplate
+	}
+	if oe.isSet("layout") {
+		profile.Layout = oe.Layout
+	}
+	return profile
+}
+
 ////////
 // write profiles
 func (pl *profileList) saveTo(writer io.Writer) error {
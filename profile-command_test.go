@@ -2,6 +2,7 @@ package main
 
 import (
 	"bytes"
+	"context"
 	"errors"
 	"github.com/stretchr/testify/assert"
 	"io"
@@ -83,6 +84,30 @@ func TestProfileCommandExecutor_OverrideExecutor_KeepingAnotherProfile(t *testin
 	assert.Equal(t, profileList{profile, another}, profiles)
 }
 
+func TestProfileCommandExecutor_OverrideExecutor_KeepsFieldsNotGiven(t *testing.T) {
+	current := Profile{
+		Name:         "work",
+		Token:        "aa00bb11cc22",
+		Dir:          "/users/ec2-user/work",
+		SSHKeyFile:   "~/.ssh/id_work",
+		APIBaseURL:   "https://github.example.com/api/v3",
+		GistHost:     "github.example.com/gist",
+		Timeout:      "10s",
+		Retries:      5,
+		NameTemplate: "{{.Slug}}",
+		Layout:       LayoutFlat,
+	}
+	given := map[string]bool{"layout": true}
+	executor := &overrideExecutor{
+		Profile: Profile{Name: "work", Layout: LayoutOwner},
+		isSet:   func(flag string) bool { return given[flag] },
+	}
+	profiles := executor.Invoke([]Profile{current})
+	expected := current
+	expected.Layout = LayoutOwner
+	assert.Equal(t, profileList{expected}, profiles)
+}
+
 func TestProfileList_WriteTo(t *testing.T) {
 	profiles := profileList{
 		{
@@ -178,3 +203,34 @@ func TestAppendOrOverrideProfilesCommand_Executor_AppendExecutor(t *testing.T) {
 	assert.Equal(t, GitHubAccessToken(""), e.Token)
 	assert.Equal(t, DestinationDir(""), e.Dir)
 }
+
+func TestAppendOrOverrideProfilesCommand_Executor_KeepsSSHKeyFile(t *testing.T) {
+	command := AppendOrOverrideProfilesCommand{
+		ProfileName: "default",
+		SSHKeyFile:  "~/.ssh/id_gist",
+	}
+	context := ProfileContext{
+		CurrentProfiles: []Profile{},
+	}
+	executor := command.executor(context)
+	profiles := executor.Invoke(context.CurrentProfiles)
+	buffer := new(bytes.Buffer)
+	err := profiles.saveTo(buffer)
+	assert.Nil(t, err)
+	assert.Equal(t, `- profile: default
+  ssh_key_file: ~/.ssh/id_gist
+`, buffer.String())
+}
+
+func TestAppendOrOverrideProfilesCommand_Run_InvalidValues(t *testing.T) {
+	commands := []AppendOrOverrideProfilesCommand{
+		{ProfileName: "default", RequestTimeout: "abc"},
+		{ProfileName: "default", RequestTimeout: "-5s"},
+		{ProfileName: "default", RetryCount: -1},
+		{ProfileName: "default", Layout: "id/owner"},
+	}
+	for _, command := range commands {
+		err := command.Run(context.Background(), ProfileContext{})
+		assert.NotNil(t, err, command)
+	}
+}
@@ -37,9 +37,17 @@ func DefaultProfileFile() ProfileFile {
 // ProfileYaml is the Profile data structure.
 // This is raw type of Profile that is not validated.
 type ProfileYaml struct {
-	Name  ProfileName       `yaml:"profile"`
-	Token GitHubAccessToken `yaml:"github_access_token,omitempty"`
-	Dir   DestinationDir    `yaml:"destination_dir,omitempty"`
+	Name       ProfileName       `yaml:"profile"`
+	Token      GitHubAccessToken `yaml:"github_access_token,omitempty"`
+	Dir        DestinationDir    `yaml:"destination_dir,omitempty"`
+	SSHKeyFile SSHKeyFile        `yaml:"ssh_key_file,omitempty"`
+	APIBaseURL APIBaseURL        `yaml:"api_base_url,omitempty"`
+	GistHost   GistHost          `yaml:"gist_host,omitempty"`
+	Timeout    RequestTimeout    `yaml:"timeout,omitempty"`
+	Retries    RetryCount        `yaml:"retries,omitempty"`
+	// NameTemplate is applied to gists cloned without name.
+	NameTemplate NameTemplate `yaml:"name_template,omitempty"`
+	Layout       Layout       `yaml:"layout,omitempty"`
 }
 
 // Profile is validated ProfileYaml.
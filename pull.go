@@ -0,0 +1,215 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"gopkg.in/src-d/go-git.v4"
+	"io"
+	"log"
+)
+
+// PullStatus is a result of pulling a gist repository.
+type PullStatus int
+
+const (
+	pullUpToDate PullStatus = iota
+	pullUpdated
+	pullDirty
+	pullDiverged
+	pullFailed
+)
+
+// String for PullStatus
+func (s *PullStatus) String() string {
+	switch *s {
+	case pullUpToDate:
+		return "up-to-date"
+	case pullUpdated:
+		return "updated"
+	case pullDirty:
+		return "dirty"
+	case pullDiverged:
+		return "diverged"
+	case pullFailed:
+		return "failed"
+	}
+	panic(fmt.Sprintf("unknown pull status: %d", s))
+}
+
+// PullCommand fetches and fast-forwards cloned gists, and refreshes their metadata.
+type PullCommand struct {
+	ProfileName
+	// Target is id or name of a gist. If empty, all gists recorded in metadata file will be pulled.
+	Target string
+	// WaitRateLimit waits until rate limit of GitHub API is reset. Otherwise metadata of remaining gists are not refreshed.
+	WaitRateLimit bool
+	Writer        io.Writer
+}
+
+// Run command of PullCommand
+func (pc *PullCommand) Run(ctx context.Context, pctx ProfileContext) error {
+	if pc.Writer == nil {
+		return errors.New("PullCommand_Run: writer is not given")
+	}
+	destinationDir, err := pctx.Dir(pc.ProfileName)
+	if err != nil {
+		return fmt.Errorf("PullCommand_Run_ProfileContext_Dir: %w", err)
+	}
+	metadataFile, err := destinationDir.Resolve(".gist")
+	if err != nil {
+		return fmt.Errorf("PullCommand_Run_Resolve: %w", err)
+	}
+	metadataIndex, err := LoadMetadataIndex(metadataFile)
+	if err != nil {
+		return fmt.Errorf("PullCommand_Run_LoadMetadataIndex: %w", err)
+	}
+	items := metadataIndex.Items()
+	targets, err := pc.targets(items)
+	if err != nil {
+		return fmt.Errorf("PullCommand_Run_Targets: %w", err)
+	}
+
+	gitHub := pctx.NewGitHub()
+	guard := newRateLimitGuard(pc.WaitRateLimit)
+	counts := make(map[PullStatus]int)
+	for _, index := range targets {
+		if ctx.Err() != nil {
+			log.Printf("pull is cancelled: %v\n", ctx.Err())
+			counts[pullFailed]++
+			break
+		}
+		md := items[index]
+		directory, err := destinationDir.Resolve(md.RelativePath())
+		if err != nil {
+			return fmt.Errorf("PullCommand_Run_ResolveRepository: %w", err)
+		}
+		status, err := pullRepository(ctx, pctx, pc.ProfileName, directory)
+		if err != nil {
+			_, _ = fmt.Fprintf(pc.Writer, "%s: %s(%v)\n", directory, status.String(), err)
+		} else {
+			_, _ = fmt.Fprintf(pc.Writer, "%s: %s\n", directory, status.String())
+		}
+		err = pc.refreshMetadata(ctx, gitHub, guard, metadataIndex, md)
+		if err != nil {
+			_, _ = fmt.Fprintf(pc.Writer, "%s: metadata not refreshed(%v)\n", directory, err)
+			status = pullFailed
+		}
+		counts[status]++
+	}
+	err = metadataIndex.Save()
+	if err != nil {
+		return fmt.Errorf("PullCommand_Run_SaveMetadata: %w", err)
+	}
+
+	_, _ = fmt.Fprintf(pc.Writer, "updated: %d, up-to-date: %d, dirty: %d, diverged: %d, failed: %d\n",
+		counts[pullUpdated], counts[pullUpToDate], counts[pullDirty], counts[pullDiverged], counts[pullFailed])
+	if counts[pullFailed] > 0 {
+		return fmt.Errorf("failed to pull %d gists", counts[pullFailed])
+	}
+	return nil
+}
+
+// refreshMetadata retrieves the gist and updates its entry in the index.
+func (pc *PullCommand) refreshMetadata(ctx context.Context, gitHub GitHub, guard *rateLimitGuard, metadataIndex *MetadataIndex, md RepositoryMetadata) error {
+	err := guard.Before(ctx)
+	if err != nil {
+		return err
+	}
+	gist, err := gitHub.GetGist(ctx, GistID(md.ID), pc.ProfileName)
+	if err != nil {
+		guard.Observe(err)
+		return err
+	}
+	refreshed, err := NewMetadataFromGist(RepositoryName(md.Name), *gist)
+	if err != nil {
+		return err
+	}
+	refreshed.inheritFrom(md)
+	starred, err := gitHub.IsStarred(ctx, GistID(md.ID), pc.ProfileName)
+	if err != nil {
+		guard.Observe(err)
+		log.Printf("failed to check star of %s: %v\n", md.ID, err)
+	} else {
+		refreshed.Starred = starred
+	}
+	metadataIndex.Upsert(*refreshed)
+	return nil
+}
+
+func (pc *PullCommand) targets(items []RepositoryMetadata) ([]int, error) {
+	if pc.Target == "" {
+		indices := make([]int, len(items))
+		for i := range items {
+			indices[i] = i
+		}
+		return indices, nil
+	}
+	index := FindMetadata(items, pc.Target)
+	if index < 0 {
+		return nil, fmt.Errorf("no gist found(id or name = %s)", pc.Target)
+	}
+	return []int{index}, nil
+}
+
+// pullRepository fetches origin and fast-forwards current branch of the repository with credentials of the profile.
+// A repository with uncommitted changes is not pulled.
+func pullRepository(ctx context.Context, pctx ProfileContext, profileName ProfileName, directory string) (PullStatus, error) {
+	repository, err := git.PlainOpen(directory)
+	if err != nil {
+		return pullFailed, fmt.Errorf("PullRepository_PlainOpen: %w", err)
+	}
+	worktree, err := repository.Worktree()
+	if err != nil {
+		return pullFailed, fmt.Errorf("PullRepository_Worktree: %w", err)
+	}
+	dirty, err := hasUncommittedChanges(worktree)
+	if err != nil {
+		return pullFailed, fmt.Errorf("PullRepository_Status: %w", err)
+	}
+	if dirty {
+		return pullDirty, nil
+	}
+	head, err := repository.Head()
+	if err != nil {
+		return pullFailed, fmt.Errorf("PullRepository_Head: %w", err)
+	}
+	auth, err := pctx.RemoteAuth(profileName, repository)
+	if err != nil {
+		return pullFailed, fmt.Errorf("PullRepository_RemoteAuth: %w", err)
+	}
+	err = runGitOperation(ctx, pctx, profileName, fmt.Sprintf("pull %s", directory), func(ctx context.Context) error {
+		return worktree.PullContext(ctx, &git.PullOptions{
+			RemoteName:    git.DefaultRemoteName,
+			ReferenceName: head.Name(),
+			Auth:          auth,
+		})
+	})
+	if errors.Is(err, git.NoErrAlreadyUpToDate) {
+		return pullUpToDate, nil
+	}
+	if errors.Is(err, git.ErrNonFastForwardUpdate) {
+		return pullDiverged, nil
+	}
+	if err != nil {
+		return pullFailed, fmt.Errorf("PullRepository_Pull: %w", err)
+	}
+	return pullUpdated, nil
+}
+
+// hasUncommittedChanges returns whether tracked files are modified or staged. Untracked files are ignored.
+func hasUncommittedChanges(worktree *git.Worktree) (bool, error) {
+	status, err := worktree.Status()
+	if err != nil {
+		return false, err
+	}
+	for _, fileStatus := range status {
+		if fileStatus.Worktree == git.Untracked && fileStatus.Staging == git.Untracked {
+			continue
+		}
+		if fileStatus.Worktree != git.Unmodified || fileStatus.Staging != git.Unmodified {
+			return true, nil
+		}
+	}
+	return false, nil
+}
@@ -0,0 +1,204 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"github.com/stretchr/testify/assert"
+	"gopkg.in/src-d/go-git.v4"
+	"gopkg.in/src-d/go-git.v4/plumbing/object"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+// prepareOriginAndClone creates a repository with a commit as origin, and clones it.
+func prepareOriginAndClone(t *testing.T, parent string) (string, string) {
+	origin := filepath.Join(parent, "origin")
+	repository, err := git.PlainInit(origin, false)
+	if err != nil {
+		t.Fatal("unexpected error@init", err)
+	}
+	commitFile(t, repository, origin, "test.md", "# test\n")
+	clone := filepath.Join(parent, "clone")
+	_, err = git.PlainClone(clone, false, &git.CloneOptions{URL: origin})
+	if err != nil {
+		t.Fatal("unexpected error@clone", err)
+	}
+	return origin, clone
+}
+
+func commitFile(t *testing.T, repository *git.Repository, directory string, name string, contents string) {
+	err := ioutil.WriteFile(filepath.Join(directory, name), []byte(contents), 0644)
+	if err != nil {
+		t.Fatal("unexpected error@write", err)
+	}
+	worktree, err := repository.Worktree()
+	if err != nil {
+		t.Fatal("unexpected error@worktree", err)
+	}
+	_, err = worktree.Add(name)
+	if err != nil {
+		t.Fatal("unexpected error@add", err)
+	}
+	_, err = worktree.Commit(fmt.Sprintf("update %s", name), &git.CommitOptions{
+		Author: &object.Signature{Name: "test-user", Email: "test-user@example.com", When: time.Now()},
+	})
+	if err != nil {
+		t.Fatal("unexpected error@commit", err)
+	}
+}
+
+var pullTestContext = ProfileContext{
+	CurrentProfiles: []Profile{{Name: "default"}},
+}
+
+func TestPullRepository_UpToDate(t *testing.T) {
+	parent, err := ioutil.TempDir("", "pull-test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() { _ = os.RemoveAll(parent) }()
+	_, clone := prepareOriginAndClone(t, parent)
+
+	status, err := pullRepository(context.Background(), pullTestContext, "default", clone)
+	assert.Nil(t, err)
+	assert.Equal(t, pullUpToDate, status)
+}
+
+func TestPullRepository_Updated(t *testing.T) {
+	parent, err := ioutil.TempDir("", "pull-test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() { _ = os.RemoveAll(parent) }()
+	origin, clone := prepareOriginAndClone(t, parent)
+	originRepository, err := git.PlainOpen(origin)
+	if err != nil {
+		t.Fatal(err)
+	}
+	commitFile(t, originRepository, origin, "test.go", "package main\n")
+
+	status, err := pullRepository(context.Background(), pullTestContext, "default", clone)
+	assert.Nil(t, err)
+	assert.Equal(t, pullUpdated, status)
+	_, err = os.Stat(filepath.Join(clone, "test.go"))
+	assert.Nil(t, err)
+}
+
+func TestPullRepository_Dirty(t *testing.T) {
+	parent, err := ioutil.TempDir("", "pull-test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() { _ = os.RemoveAll(parent) }()
+	_, clone := prepareOriginAndClone(t, parent)
+	err = ioutil.WriteFile(filepath.Join(clone, "test.md"), []byte("# modified\n"), 0644)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	status, err := pullRepository(context.Background(), pullTestContext, "default", clone)
+	assert.Nil(t, err)
+	assert.Equal(t, pullDirty, status)
+}
+
+func TestPullRepository_Diverged(t *testing.T) {
+	parent, err := ioutil.TempDir("", "pull-test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() { _ = os.RemoveAll(parent) }()
+	origin, clone := prepareOriginAndClone(t, parent)
+	originRepository, err := git.PlainOpen(origin)
+	if err != nil {
+		t.Fatal(err)
+	}
+	commitFile(t, originRepository, origin, "test.go", "package main\n")
+	cloneRepository, err := git.PlainOpen(clone)
+	if err != nil {
+		t.Fatal(err)
+	}
+	commitFile(t, cloneRepository, clone, "local.md", "# local\n")
+
+	status, err := pullRepository(context.Background(), pullTestContext, "default", clone)
+	assert.Nil(t, err)
+	assert.Equal(t, pullDiverged, status)
+}
+
+func TestPullCommand_Run_RefreshesMetadata(t *testing.T) {
+	parent, err := ioutil.TempDir("", "pull-test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() { _ = os.RemoveAll(parent) }()
+	prepareOriginAndClone(t, parent)
+	metadataFile := filepath.Join(parent, ".gist")
+	md := RepositoryMetadata{ID: "aa11", Name: "clone", Owner: "old-user"}
+	saveTestMetadata(t, metadataFile, md)
+	ctx, stop := startTestGitHubServer(func(writer http.ResponseWriter, request *http.Request) {
+		if request.URL.Path == "/gists/aa11/star" {
+			writer.WriteHeader(http.StatusNoContent)
+			return
+		}
+		assert.Equal(t, "/gists/aa11", request.URL.Path)
+		_, _ = writer.Write([]byte(`{"id":"aa11","created_at":"2020-01-01T00:00:00Z","owner":{"login":"new-user"}}`))
+	})
+	defer stop()
+	ctx.CurrentProfiles[0].Dir = DestinationDir(parent)
+
+	buffer := new(bytes.Buffer)
+	command := PullCommand{ProfileName: "default", Target: "clone", Writer: buffer}
+	err = command.Run(context.Background(), ctx)
+	assert.Nil(t, err)
+	assert.Equal(t, fmt.Sprintf("%s/clone: up-to-date\n", parent)+
+		"updated: 0, up-to-date: 1, dirty: 0, diverged: 0, failed: 0\n", buffer.String())
+	items, err := LoadMetadataFrom(metadataFile)
+	assert.Nil(t, err)
+	// visibility of the gist recorded without it is refreshed
+	secret := false
+	assert.Equal(t, []RepositoryMetadata{{ID: "aa11", Name: "clone", Owner: "new-user", Created: 1577836800, Starred: true, Public: &secret}}, items)
+}
+
+func TestPullCommand_Run_RefreshFailure(t *testing.T) {
+	parent, err := ioutil.TempDir("", "pull-test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() { _ = os.RemoveAll(parent) }()
+	prepareOriginAndClone(t, parent)
+	metadataIndex, err := LoadMetadataIndex(filepath.Join(parent, ".gist"))
+	assert.Nil(t, err)
+	metadataIndex.Upsert(RepositoryMetadata{ID: "aa11", Name: "clone"})
+	assert.Nil(t, metadataIndex.Save())
+	ctx, stop := startTestGitHubServer(func(writer http.ResponseWriter, request *http.Request) {
+		writer.WriteHeader(http.StatusNotFound)
+		_, _ = writer.Write([]byte(`{"message":"Not Found"}`))
+	})
+	defer stop()
+	ctx.CurrentProfiles[0].Dir = DestinationDir(parent)
+
+	buffer := new(bytes.Buffer)
+	command := PullCommand{ProfileName: "default", Writer: buffer}
+	err = command.Run(context.Background(), ctx)
+	assert.NotNil(t, err)
+	lines := strings.Split(strings.TrimSpace(buffer.String()), "\n")
+	assert.Equal(t, 3, len(lines), buffer.String())
+	assert.Equal(t, fmt.Sprintf("%s/clone: up-to-date", parent), lines[0])
+	assert.True(t, strings.HasPrefix(lines[1], fmt.Sprintf("%s/clone: metadata not refreshed(", parent)), lines[1])
+	// a gist is counted only once
+	assert.Equal(t, "updated: 0, up-to-date: 0, dirty: 0, diverged: 0, failed: 1", lines[2])
+}
+
+func TestPullCommand_Run_UnknownTarget(t *testing.T) {
+	ctx := ProfileContext{
+		CurrentProfiles: []Profile{{Name: "default", Dir: "build/test/not-existing"}},
+	}
+	command := PullCommand{ProfileName: "default", Target: "aa11", Writer: new(bytes.Buffer)}
+	err := command.Run(context.Background(), ctx)
+	assert.NotNil(t, err)
+}
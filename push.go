@@ -0,0 +1,163 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"gopkg.in/src-d/go-git.v4"
+	format "gopkg.in/src-d/go-git.v4/plumbing/format/config"
+	"gopkg.in/src-d/go-git.v4/plumbing/object"
+	"io"
+	"os"
+	"sort"
+	"strings"
+	"time"
+)
+
+// PushCommand commits all changes of a cloned gist, and pushes them to GitHub.
+type PushCommand struct {
+	ProfileName
+	// Target is id or name of a gist. If empty, the repository containing WorkingDir is pushed.
+	Target     string
+	WorkingDir string
+	// Message is commit message. If empty, a message is generated from changed files.
+	Message string
+	Writer  io.Writer
+}
+
+// Run command of PushCommand
+func (pc *PushCommand) Run(ctx context.Context, pctx ProfileContext) error {
+	if pc.Writer == nil {
+		return errors.New("PushCommand_Run: writer is not given")
+	}
+	repository, err := pc.openRepository(pctx)
+	if err != nil {
+		return fmt.Errorf("PushCommand_Run_OpenRepository: %w", err)
+	}
+	worktree, err := repository.Worktree()
+	if err != nil {
+		return fmt.Errorf("PushCommand_Run_Worktree: %w", err)
+	}
+	changed, err := stageAll(worktree)
+	if err != nil {
+		return fmt.Errorf("PushCommand_Run_Stage: %w", err)
+	}
+	if len(changed) > 0 {
+		signature, err := pc.signature(pctx, repository)
+		if err != nil {
+			return fmt.Errorf("PushCommand_Run_Signature: %w", err)
+		}
+		message := pc.Message
+		if message == "" {
+			message = fmt.Sprintf("Update %s", strings.Join(changed, ", "))
+		}
+		hash, err := worktree.Commit(message, &git.CommitOptions{Author: signature})
+		if err != nil {
+			return fmt.Errorf("PushCommand_Run_Commit: %w", err)
+		}
+		_, _ = fmt.Fprintf(pc.Writer, "committed %s: %s\n", hash.String()[:7], message)
+	}
+
+	auth, err := pctx.RemoteAuth(pc.ProfileName, repository)
+	if err != nil {
+		return fmt.Errorf("PushCommand_Run_RemoteAuth: %w", err)
+	}
+	err = runGitOperation(ctx, pctx, pc.ProfileName, "push", func(ctx context.Context) error {
+		return repository.PushContext(ctx, &git.PushOptions{
+			RemoteName: git.DefaultRemoteName,
+			Auth:       auth,
+		})
+	})
+	if errors.Is(err, git.NoErrAlreadyUpToDate) {
+		_, _ = fmt.Fprintln(pc.Writer, "already up-to-date")
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("PushCommand_Run_Push: %w", err)
+	}
+	_, _ = fmt.Fprintln(pc.Writer, "pushed")
+	return nil
+}
+
+func (pc *PushCommand) openRepository(pctx ProfileContext) (*git.Repository, error) {
+	if pc.Target == "" {
+		return git.PlainOpenWithOptions(pc.WorkingDir, &git.PlainOpenOptions{DetectDotGit: true})
+	}
+	destinationDir, err := pctx.Dir(pc.ProfileName)
+	if err != nil {
+		return nil, fmt.Errorf("ProfileContext_Dir: %w", err)
+	}
+	metadataFile, err := destinationDir.Resolve(".gist")
+	if err != nil {
+		return nil, fmt.Errorf("Resolve: %w", err)
+	}
+	metadataIndex, err := LoadMetadataIndex(metadataFile)
+	if err != nil {
+		return nil, fmt.Errorf("LoadMetadataIndex: %w", err)
+	}
+	md, found := metadataIndex.Find(pc.Target)
+	if !found {
+		return nil, fmt.Errorf("no gist found(id or name = %s)", pc.Target)
+	}
+	directory, err := destinationDir.Resolve(md.RelativePath())
+	if err != nil {
+		return nil, fmt.Errorf("ResolveRepository: %w", err)
+	}
+	return git.PlainOpen(directory)
+}
+
+// stageAll stages all modified, added and deleted files, and returns their names.
+func stageAll(worktree *git.Worktree) ([]string, error) {
+	status, err := worktree.Status()
+	if err != nil {
+		return nil, err
+	}
+	changed := make([]string, 0)
+	for path, fileStatus := range status {
+		if fileStatus.Worktree != git.Unmodified {
+			_, err = worktree.Add(path)
+			if err != nil {
+				return nil, err
+			}
+		}
+		changed = append(changed, path)
+	}
+	sort.Strings(changed)
+	return changed, nil
+}
+
+// signature determines author from user section of repository config, or global config($HOME/.gitconfig).
+func (pc *PushCommand) signature(pctx ProfileContext, repository *git.Repository) (*object.Signature, error) {
+	repositoryConfig, err := repository.Config()
+	if err != nil {
+		return nil, err
+	}
+	configs := []*format.Config{repositoryConfig.Raw}
+	globalConfig, err := loadGlobalGitConfig(pctx.UserHome)
+	if err == nil {
+		configs = append(configs, globalConfig)
+	}
+	for _, cfg := range configs {
+		user := cfg.Section("user")
+		name := user.Option("name")
+		email := user.Option("email")
+		if name != "" && email != "" {
+			return &object.Signature{Name: name, Email: email, When: time.Now()}, nil
+		}
+	}
+	return nil, errors.New("user.name and user.email are not configured in git config")
+}
+
+func loadGlobalGitConfig(home UserHome) (*format.Config, error) {
+	file, err := os.Open(fmt.Sprintf("%s/.gitconfig", home))
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = file.Close() }()
+	cfg := format.New()
+	err = format.NewDecoder(file).Decode(cfg)
+	if err != nil {
+		return nil, err
+	}
+	return cfg, nil
+}
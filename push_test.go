@@ -0,0 +1,122 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"github.com/stretchr/testify/assert"
+	"gopkg.in/src-d/go-git.v4"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// prepareBareOriginAndClone creates a bare repository with a commit as origin, and clones it with user config.
+func prepareBareOriginAndClone(t *testing.T, parent string) (string, string) {
+	seed, _ := prepareOriginAndClone(t, parent)
+	origin := filepath.Join(parent, "bare")
+	_, err := git.PlainClone(origin, true, &git.CloneOptions{URL: seed})
+	if err != nil {
+		t.Fatal("unexpected error@bare clone", err)
+	}
+	clone := filepath.Join(parent, "work")
+	repository, err := git.PlainClone(clone, false, &git.CloneOptions{URL: origin})
+	if err != nil {
+		t.Fatal("unexpected error@clone", err)
+	}
+	cfg, err := repository.Config()
+	if err != nil {
+		t.Fatal("unexpected error@config", err)
+	}
+	cfg.Raw.Section("user").SetOption("name", "test-user").SetOption("email", "test-user@example.com")
+	err = repository.Storer.SetConfig(cfg)
+	if err != nil {
+		t.Fatal("unexpected error@set config", err)
+	}
+	return origin, clone
+}
+
+func headMessage(t *testing.T, directory string) string {
+	repository, err := git.PlainOpen(directory)
+	if err != nil {
+		t.Fatal(err)
+	}
+	head, err := repository.Head()
+	if err != nil {
+		t.Fatal(err)
+	}
+	commit, err := repository.CommitObject(head.Hash())
+	if err != nil {
+		t.Fatal(err)
+	}
+	return commit.Message
+}
+
+func TestPushCommand_Run_CommitsAndPushes(t *testing.T) {
+	parent, err := ioutil.TempDir("", "push-test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() { _ = os.RemoveAll(parent) }()
+	origin, clone := prepareBareOriginAndClone(t, parent)
+	err = ioutil.WriteFile(filepath.Join(clone, "new.go"), []byte("package main\n"), 0644)
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = os.Remove(filepath.Join(clone, "test.md"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	md := RepositoryMetadata{ID: "aa11", Name: "work"}
+	saveTestMetadata(t, filepath.Join(parent, ".gist"), md)
+	ctx := ProfileContext{
+		CurrentProfiles: []Profile{{Name: "default", Dir: DestinationDir(parent)}},
+	}
+
+	buffer := new(bytes.Buffer)
+	command := PushCommand{ProfileName: "default", Target: "aa11", Writer: buffer}
+	err = command.Run(context.Background(), ctx)
+	assert.Nil(t, err)
+	assert.Equal(t, "Update new.go, test.md", headMessage(t, origin))
+	assert.True(t, strings.HasSuffix(buffer.String(), "pushed\n"), buffer.String())
+}
+
+func TestPushCommand_Run_WorkingDirWithMessage(t *testing.T) {
+	parent, err := ioutil.TempDir("", "push-test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() { _ = os.RemoveAll(parent) }()
+	origin, clone := prepareBareOriginAndClone(t, parent)
+	err = ioutil.WriteFile(filepath.Join(clone, "test.md"), []byte("# modified\n"), 0644)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx := ProfileContext{
+		CurrentProfiles: []Profile{{Name: "default", Dir: DestinationDir(parent)}},
+	}
+
+	command := PushCommand{ProfileName: "default", WorkingDir: clone, Message: "fix test.md", Writer: new(bytes.Buffer)}
+	err = command.Run(context.Background(), ctx)
+	assert.Nil(t, err)
+	assert.Equal(t, "fix test.md", headMessage(t, origin))
+}
+
+func TestPushCommand_Run_NothingToPush(t *testing.T) {
+	parent, err := ioutil.TempDir("", "push-test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() { _ = os.RemoveAll(parent) }()
+	_, clone := prepareBareOriginAndClone(t, parent)
+	ctx := ProfileContext{
+		CurrentProfiles: []Profile{{Name: "default", Dir: DestinationDir(parent)}},
+	}
+
+	buffer := new(bytes.Buffer)
+	command := PushCommand{ProfileName: "default", WorkingDir: clone, Writer: buffer}
+	err = command.Run(context.Background(), ctx)
+	assert.Nil(t, err)
+	assert.Equal(t, "already up-to-date\n", buffer.String())
+}
@@ -0,0 +1,144 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+)
+
+// RelayoutCommand moves cloned gists into directories of the new layout, and records it in metadata and profile.
+type RelayoutCommand struct {
+	ProfileName
+	Layout
+	Writer io.Writer
+}
+
+// Run command of RelayoutCommand
+// Metadata is saved after each move, so that it follows directories even if the command stops halfway.
+// The layout is saved into profile only when all gists are moved.
+func (rc *RelayoutCommand) Run(ctx context.Context, pctx ProfileContext) error {
+	if rc.Writer == nil {
+		return errors.New("RelayoutCommand_Run: writer is not given")
+	}
+	layout, err := NewLayout(string(rc.Layout))
+	if err != nil {
+		return fmt.Errorf("RelayoutCommand_Run_NewLayout: %w", err)
+	}
+	destinationDir, err := pctx.Dir(rc.ProfileName)
+	if err != nil {
+		return fmt.Errorf("RelayoutCommand_Run_ProfileContext_Dir: %w", err)
+	}
+	metadataFile, err := destinationDir.Resolve(".gist")
+	if err != nil {
+		return fmt.Errorf("RelayoutCommand_Run_Resolve: %w", err)
+	}
+	metadataIndex, err := LoadMetadataIndex(metadataFile)
+	if err != nil {
+		return fmt.Errorf("RelayoutCommand_Run_LoadMetadataIndex: %w", err)
+	}
+
+	moved := 0
+	unchanged := 0
+	failed := 0
+	for _, md := range metadataIndex.Items() {
+		if ctx.Err() != nil {
+			_, _ = fmt.Fprintf(rc.Writer, "moved: %d, unchanged: %d, failed: %d\n", moved, unchanged, failed)
+			return fmt.Errorf("relayout is cancelled: %w", ctx.Err())
+		}
+		from := md.RelativePath()
+		to := layout.Path(md, md.DirName())
+		if from == to {
+			unchanged++
+			continue
+		}
+		// the gist is not moved into the working tree of another gist
+		err := checkLayoutParent(metadataIndex.Items(), md.ID, layout.Parent(md))
+		if err == nil {
+			err = moveRepository(destinationDir, from, to)
+		}
+		if err != nil {
+			_, _ = fmt.Fprintf(rc.Writer, "%s: failed(%v)\n", from, err)
+			failed++
+			continue
+		}
+		md.Path = ""
+		if to != md.DirName() {
+			md.Path = to
+		}
+		metadataIndex.Upsert(md)
+		err = metadataIndex.Save()
+		if err != nil {
+			// move it back, so that metadata does not lose the directory
+			_ = moveRepository(destinationDir, to, from)
+			return fmt.Errorf("RelayoutCommand_Run_SaveMetadata(%s): %w", from, err)
+		}
+		moved++
+		_, _ = fmt.Fprintf(rc.Writer, "moved %s to %s\n", from, to)
+	}
+	_, _ = fmt.Fprintf(rc.Writer, "moved: %d, unchanged: %d, failed: %d\n", moved, unchanged, failed)
+	if failed > 0 {
+		return fmt.Errorf("failed to move %d gists, layout is not saved to profile", failed)
+	}
+	err = rc.saveLayout(pctx, layout)
+	if err != nil {
+		return fmt.Errorf("RelayoutCommand_Run_SaveLayout: %w", err)
+	}
+	return nil
+}
+
+// saveLayout writes the layout into profile, so that gists cloned later are placed in the same layout.
+func (rc *RelayoutCommand) saveLayout(pctx ProfileContext, layout Layout) error {
+	current, err := pctx.Layout(rc.ProfileName)
+	if err != nil || current == layout {
+		return err
+	}
+	return pctx.ProfileFile.Update(func(profiles []Profile) []Profile {
+		for i, profile := range profiles {
+			if profile.Name == rc.ProfileName {
+				profiles[i].Layout = layout
+				return profiles
+			}
+		}
+		// the profile is not saved in the file yet
+		for _, profile := range pctx.CurrentProfiles {
+			if profile.Name == rc.ProfileName {
+				profile.Layout = layout
+				profiles = append(profiles, profile)
+			}
+		}
+		return profiles
+	})
+}
+
+// moveRepository moves directory of a gist, and removes parent directories left empty.
+func moveRepository(destinationDir DestinationDir, from string, to string) error {
+	source, err := destinationDir.Resolve(from)
+	if err != nil {
+		return err
+	}
+	target, err := destinationDir.Resolve(to)
+	if err != nil {
+		return err
+	}
+	if _, err := os.Stat(target); err == nil {
+		return fmt.Errorf("%s already exists", to)
+	}
+	err = os.MkdirAll(filepath.Dir(target), 0755)
+	if err != nil {
+		return err
+	}
+	err = os.Rename(source, target)
+	if err != nil {
+		return err
+	}
+	for parent := filepath.Dir(from); parent != "." && parent != "/"; parent = filepath.Dir(parent) {
+		directory, err := destinationDir.Resolve(parent)
+		if err != nil || os.Remove(directory) != nil {
+			break
+		}
+	}
+	return nil
+}
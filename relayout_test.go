@@ -0,0 +1,138 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"github.com/stretchr/testify/assert"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func prepareRelayoutTestContext(t *testing.T) (ProfileContext, string) {
+	dir := prepareDeleteTestDir(t)
+	items, err := LoadMetadataFrom(filepath.Join(dir, ".gist"))
+	assert.Nil(t, err)
+	items[0].Owner = "mike-neck"
+	items[1].Owner = "test-user"
+	saveTestMetadata(t, filepath.Join(dir, ".gist"), items...)
+	ctx := ProfileContext{
+		ProfileFile:     ProfileFile(filepath.Join(dir, ".gist.yml")),
+		CurrentProfiles: []Profile{{Name: "default", Dir: DestinationDir(dir)}},
+	}
+	return ctx, dir
+}
+
+func TestRelayoutCommand_Run(t *testing.T) {
+	ctx, dir := prepareRelayoutTestContext(t)
+	defer func() { _ = os.RemoveAll(dir) }()
+
+	buffer := new(bytes.Buffer)
+	command := RelayoutCommand{ProfileName: "default", Layout: LayoutOwner, Writer: buffer}
+	err := command.Run(context.Background(), ctx)
+	assert.Nil(t, err, buffer.String())
+	assert.Contains(t, buffer.String(), "moved first to mike-neck/first")
+	assert.Contains(t, buffer.String(), "moved: 2, unchanged: 0, failed: 0")
+	_, err = os.Stat(filepath.Join(dir, "mike-neck", "first"))
+	assert.Nil(t, err)
+	_, err = os.Stat(filepath.Join(dir, "first"))
+	assert.True(t, os.IsNotExist(err))
+
+	items, err := LoadMetadataFrom(filepath.Join(dir, ".gist"))
+	assert.Nil(t, err)
+	assert.Equal(t, "mike-neck/first", items[0].Path)
+	assert.Equal(t, "test-user/bb22", items[1].Path)
+	profiles, err := ctx.ProfileFile.LoadProfiles()
+	assert.Nil(t, err)
+	assert.Equal(t, LayoutOwner, profiles[0].Layout)
+
+	// back to flat, then empty parent directories are removed.
+	ctx.CurrentProfiles = profiles
+	buffer.Reset()
+	command = RelayoutCommand{ProfileName: "default", Layout: LayoutFlat, Writer: buffer}
+	err = command.Run(context.Background(), ctx)
+	assert.Nil(t, err, buffer.String())
+	items, err = LoadMetadataFrom(filepath.Join(dir, ".gist"))
+	assert.Nil(t, err)
+	assert.Equal(t, "", items[0].Path)
+	_, err = os.Stat(filepath.Join(dir, "first"))
+	assert.Nil(t, err)
+	_, err = os.Stat(filepath.Join(dir, "mike-neck"))
+	assert.True(t, os.IsNotExist(err))
+}
+
+func TestRelayoutCommand_Run_Collision(t *testing.T) {
+	ctx, dir := prepareRelayoutTestContext(t)
+	defer func() { _ = os.RemoveAll(dir) }()
+	assert.Nil(t, os.MkdirAll(filepath.Join(dir, "test-user", "bb22"), 0755))
+
+	buffer := new(bytes.Buffer)
+	command := RelayoutCommand{ProfileName: "default", Layout: LayoutOwner, Writer: buffer}
+	err := command.Run(context.Background(), ctx)
+	assert.NotNil(t, err)
+	assert.Contains(t, buffer.String(), "moved: 1, unchanged: 0, failed: 1")
+	items, err := LoadMetadataFrom(filepath.Join(dir, ".gist"))
+	assert.Nil(t, err)
+	assert.Equal(t, "mike-neck/first", items[0].Path)
+	assert.Equal(t, "", items[1].Path)
+	_, err = os.Stat(filepath.Join(dir, "bb22"))
+	assert.Nil(t, err)
+	// layout is not saved, so that gists cloned later are not placed in the half applied layout.
+	_, err = os.Stat(filepath.Join(dir, ".gist.yml"))
+	assert.True(t, os.IsNotExist(err))
+}
+
+func TestRelayoutCommand_Run_Cancelled(t *testing.T) {
+	ctx, dir := prepareRelayoutTestContext(t)
+	defer func() { _ = os.RemoveAll(dir) }()
+	cancelled, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	buffer := new(bytes.Buffer)
+	command := RelayoutCommand{ProfileName: "default", Layout: LayoutOwner, Writer: buffer}
+	err := command.Run(cancelled, ctx)
+	assert.True(t, errors.Is(err, context.Canceled))
+	assert.Contains(t, buffer.String(), "moved: 0, unchanged: 0, failed: 0")
+	_, err = os.Stat(filepath.Join(dir, "first"))
+	assert.Nil(t, err)
+	_, err = os.Stat(filepath.Join(dir, ".gist.yml"))
+	assert.True(t, os.IsNotExist(err))
+}
+
+func TestRelayoutCommand_Run_ParentIsAnotherGist(t *testing.T) {
+	dir, err := ioutil.TempDir("", "relayout-test")
+	assert.Nil(t, err)
+	defer func() { _ = os.RemoveAll(dir) }()
+	// owner directory of bb22 is the directory of gist aa11 named alice
+	for _, md := range []RepositoryMetadata{{ID: "bb22", Owner: "alice"}, {ID: "aa11", Name: "alice", Owner: "bob"}} {
+		assert.Nil(t, os.MkdirAll(filepath.Join(dir, md.DirName()), 0755))
+		saveTestMetadata(t, filepath.Join(dir, ".gist"), md)
+	}
+	ctx := ProfileContext{
+		ProfileFile:     ProfileFile(filepath.Join(dir, ".gist.yml")),
+		CurrentProfiles: []Profile{{Name: "default", Dir: DestinationDir(dir)}},
+	}
+
+	buffer := new(bytes.Buffer)
+	command := RelayoutCommand{ProfileName: "default", Layout: LayoutOwner, Writer: buffer}
+	err = command.Run(context.Background(), ctx)
+	assert.NotNil(t, err)
+	assert.Contains(t, buffer.String(), "bb22: failed(directory alice is used by gist aa11)")
+	assert.Contains(t, buffer.String(), "moved: 1, unchanged: 0, failed: 1")
+	items, err := LoadMetadataFrom(filepath.Join(dir, ".gist"))
+	assert.Nil(t, err)
+	assert.Equal(t, "", items[0].Path)
+	assert.Equal(t, "bob/alice", items[1].Path)
+	_, err = os.Stat(filepath.Join(dir, "bb22"))
+	assert.Nil(t, err)
+	_, err = os.Stat(filepath.Join(dir, "bob", "alice", "bb22"))
+	assert.True(t, os.IsNotExist(err))
+}
+
+func TestRelayoutCommand_Run_InvalidLayout(t *testing.T) {
+	command := RelayoutCommand{ProfileName: "default", Layout: "id/owner", Writer: new(bytes.Buffer)}
+	err := command.Run(context.Background(), ProfileContext{})
+	assert.NotNil(t, err)
+}
@@ -0,0 +1,104 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+)
+
+// RemoteListCommand shows gists on GitHub with whether each of them is already cloned or not.
+type RemoteListCommand struct {
+	ProfileName
+	GistQuery
+	OutputFormat
+	Writer io.Writer
+}
+
+// RemoteGist is a gist on GitHub with its clone status.
+type RemoteGist struct {
+	ID          string `json:"id" xml:"id" yaml:"id"`
+	Description string `json:"description" xml:"description" yaml:"description"`
+	Owner       string `json:"owner" xml:"owner" yaml:"owner"`
+	Created     string `json:"created_at" xml:"created_at" yaml:"created_at"`
+	GitURL      string `json:"git_url" xml:"git_url" yaml:"git_url"`
+	Cloned      bool   `json:"cloned" xml:"cloned" yaml:"cloned"`
+	Public      bool   `json:"public" xml:"public" yaml:"public"`
+	Updated     string `json:"updated_at" xml:"updated_at" yaml:"updated_at"`
+}
+
+// Run command of RemoteListCommand
+func (rc *RemoteListCommand) Run(ctx context.Context, pctx ProfileContext) error {
+	if rc.Writer == nil {
+		return errors.New("RemoteListCommand_Run: writer is not given")
+	}
+	formatter, err := NewFormatter(rc.OutputFormat)
+	if err != nil {
+		return fmt.Errorf("RemoteListCommand_Run_NewFormatter: %w", err)
+	}
+	destinationDir, err := pctx.Dir(rc.ProfileName)
+	if err != nil {
+		return fmt.Errorf("RemoteListCommand_Run_ProfileContext_Dir: %w", err)
+	}
+	metadataFile, err := destinationDir.Resolve(".gist")
+	if err != nil {
+		return fmt.Errorf("RemoteListCommand_Run_Resolve: %w", err)
+	}
+	metadataIndex, err := LoadMetadataIndex(metadataFile)
+	if err != nil {
+		return fmt.Errorf("RemoteListCommand_Run_LoadMetadataIndex: %w", err)
+	}
+	items := metadataIndex.Items()
+	cloned := make(map[string]bool, len(items))
+	for _, md := range items {
+		cloned[md.ID] = true
+	}
+
+	gitHub := pctx.NewGitHub()
+	gists, err := gitHub.ListGists(ctx, rc.GistQuery, rc.ProfileName)
+	if err != nil {
+		return fmt.Errorf("RemoteListCommand_Run_ListGists: %w", err)
+	}
+	records := make(remoteGistRecords, len(gists))
+	for i, gist := range gists {
+		records[i] = RemoteGist{
+			ID:          gist.ID,
+			Description: gist.Description,
+			Owner:       gist.Owner.Login,
+			Created:     gist.CreatedAt,
+			GitURL:      gist.GitURL,
+			Cloned:      cloned[gist.ID],
+			Public:      gist.Public,
+			Updated:     gist.UpdatedAt,
+		}
+	}
+	err = formatter.Format(rc.Writer, records)
+	if err != nil {
+		return fmt.Errorf("RemoteListCommand_Run_Format: %w", err)
+	}
+	return nil
+}
+
+type remoteGistRecords []RemoteGist
+
+func (records remoteGistRecords) Items() interface{} {
+	return []RemoteGist(records)
+}
+
+func (records remoteGistRecords) XMLNames() (string, string) {
+	return "gists", "gist"
+}
+
+func (records remoteGistRecords) Header() []string {
+	return []string{"id", "description", "owner", "created_at", "git_url", "cloned", "public", "updated_at"}
+}
+
+func (records remoteGistRecords) Rows() [][]string {
+	rows := make([][]string, len(records))
+	for i, gist := range records {
+		rows[i] = []string{gist.ID, gist.Description, gist.Owner, gist.Created, gist.GitURL, strconv.FormatBool(gist.Cloned),
+			strconv.FormatBool(gist.Public), gist.Updated}
+	}
+	return rows
+}
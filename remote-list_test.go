@@ -0,0 +1,41 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"github.com/stretchr/testify/assert"
+	"net/http"
+	"os"
+	"testing"
+)
+
+func TestRemoteListCommand_Run(t *testing.T) {
+	err := os.MkdirAll("build/test/github", 0755)
+	if err != nil {
+		assert.Fail(t, "unexpected error@mkdir", err)
+		return
+	}
+	_ = os.Remove("build/test/github/.gist")
+	cloned := RepositoryMetadata{ID: "aa11", Owner: "test-user"}
+	saveTestMetadata(t, "build/test/github/.gist", cloned)
+	ctx, stop := startTestGitHubServer(func(writer http.ResponseWriter, request *http.Request) {
+		_, _ = writer.Write([]byte(`[
+{"id":"aa11","description":"cloned","created_at":"2020-01-01T00:00:00Z","git_pull_url":"https://gist.github.com/aa11.git","owner":{"login":"test-user"},"public":true,"updated_at":"2020-01-03T00:00:00Z"},
+{"id":"bb22","description":"not cloned","created_at":"2020-02-01T00:00:00Z","git_pull_url":"https://gist.github.com/bb22.git","owner":{"login":"test-user"}}
+]`))
+	})
+	defer stop()
+
+	buffer := new(bytes.Buffer)
+	command := RemoteListCommand{
+		ProfileName:  "default",
+		OutputFormat: "tsv",
+		Writer:       buffer,
+	}
+	err = command.Run(context.Background(), ctx)
+	assert.Nil(t, err)
+	assert.Equal(t, "id\tdescription\towner\tcreated_at\tgit_url\tcloned\tpublic\tupdated_at\n"+
+		"aa11\tcloned\ttest-user\t2020-01-01T00:00:00Z\thttps://gist.github.com/aa11.git\ttrue\ttrue\t2020-01-03T00:00:00Z\n"+
+		"bb22\tnot cloned\ttest-user\t2020-02-01T00:00:00Z\thttps://gist.github.com/bb22.git\tfalse\tfalse\t\n",
+		buffer.String())
+}
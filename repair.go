@@ -0,0 +1,154 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"gopkg.in/src-d/go-git.v4"
+	"io"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// RepairCommand records metadata of cloned gists which are missing in metadata file.
+// Gists are found from git repositories under the destination directory in any layout, by url of their origin.
+type RepairCommand struct {
+	ProfileName
+	Writer io.Writer
+}
+
+// Run command of RepairCommand
+func (rc *RepairCommand) Run(ctx context.Context, pctx ProfileContext) error {
+	if rc.Writer == nil {
+		return errors.New("RepairCommand_Run: writer is not given")
+	}
+	destinationDir, err := pctx.Dir(rc.ProfileName)
+	if err != nil {
+		return fmt.Errorf("RepairCommand_Run_ProfileContext_Dir: %w", err)
+	}
+	metadataFile, err := destinationDir.Resolve(".gist")
+	if err != nil {
+		return fmt.Errorf("RepairCommand_Run_Resolve: %w", err)
+	}
+	metadataIndex, err := LoadMetadataIndex(metadataFile)
+	if err != nil {
+		return fmt.Errorf("RepairCommand_Run_LoadMetadataIndex: %w", err)
+	}
+	paths, err := untrackedRepositories(destinationDir, metadataIndex.Items())
+	if err != nil {
+		return fmt.Errorf("RepairCommand_Run_UntrackedRepositories: %w", err)
+	}
+
+	gitHub := pctx.NewGitHub()
+	repaired := 0
+	failed := 0
+	for _, path := range paths {
+		directory, err := destinationDir.Resolve(path)
+		if err != nil {
+			return fmt.Errorf("RepairCommand_Run_ResolveRepository: %w", err)
+		}
+		gistID, err := originGistID(directory)
+		if err != nil {
+			log.Printf("skip %s: %v\n", directory, err)
+			continue
+		}
+		if _, found := metadataIndex.Find(string(gistID)); found {
+			_, _ = fmt.Fprintf(rc.Writer, "%s: gist %s is already recorded in another directory\n", directory, gistID)
+			continue
+		}
+		gist, err := gitHub.GetGist(ctx, gistID, rc.ProfileName)
+		if err != nil {
+			_, _ = fmt.Fprintf(rc.Writer, "%s: failed(%v)\n", directory, err)
+			failed++
+			continue
+		}
+		name := filepath.Base(path)
+		repositoryName := RepositoryName("")
+		if name != string(gistID) {
+			repositoryName = RepositoryName(name)
+		}
+		md, err := NewMetadataFromGist(repositoryName, *gist)
+		if err != nil {
+			_, _ = fmt.Fprintf(rc.Writer, "%s: failed(%v)\n", directory, err)
+			failed++
+			continue
+		}
+		if path != md.DirName() {
+			md.Path = path
+		}
+		metadataIndex.Upsert(*md)
+		repaired++
+		_, _ = fmt.Fprintf(rc.Writer, "%s: repaired %s\n", directory, gistID)
+	}
+	if repaired > 0 {
+		err = metadataIndex.Save()
+		if err != nil {
+			return fmt.Errorf("RepairCommand_Run_SaveMetadata: %w", err)
+		}
+	}
+
+	_, _ = fmt.Fprintf(rc.Writer, "repaired: %d, failed: %d\n", repaired, failed)
+	if failed > 0 {
+		return fmt.Errorf("failed to repair %d gists", failed)
+	}
+	return nil
+}
+
+// untrackedRepositories finds directories of git repositories which are not recorded in metadata,
+// and returns their paths relative to destination directory. Hidden directories are ignored.
+func untrackedRepositories(destinationDir DestinationDir, items []RepositoryMetadata) ([]string, error) {
+	tracked := make(map[string]bool, len(items))
+	for _, md := range items {
+		tracked[md.RelativePath()] = true
+	}
+	root := filepath.Clean(string(destinationDir))
+	paths := make([]string, 0)
+	err := filepath.Walk(root, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if path == root || !info.IsDir() {
+			return nil
+		}
+		relative, err := filepath.Rel(root, path)
+		if err != nil {
+			return err
+		}
+		relative = filepath.ToSlash(relative)
+		if strings.HasPrefix(info.Name(), ".") || tracked[relative] {
+			return filepath.SkipDir
+		}
+		if _, err := os.Stat(filepath.Join(path, ".git")); err == nil {
+			paths = append(paths, relative)
+			return filepath.SkipDir
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return paths, nil
+}
+
+// originGistID returns id of the gist from url of origin of the repository.
+func originGistID(directory string) (GistID, error) {
+	repository, err := git.PlainOpen(directory)
+	if err != nil {
+		return "", fmt.Errorf("OriginGistID_PlainOpen: %w", err)
+	}
+	remote, err := repository.Remote(git.DefaultRemoteName)
+	if err != nil {
+		return "", fmt.Errorf("OriginGistID_Remote: %w", err)
+	}
+	urls := remote.Config().URLs
+	if len(urls) == 0 {
+		return "", fmt.Errorf("OriginGistID: no url for %s", git.DefaultRemoteName)
+	}
+	gistID, err := ParseGistReference(urls[0])
+	if err != nil {
+		return "", fmt.Errorf("OriginGistID_ParseGistReference: %w", err)
+	}
+	return *gistID, nil
+}
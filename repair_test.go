@@ -0,0 +1,68 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"github.com/stretchr/testify/assert"
+	"gopkg.in/src-d/go-git.v4"
+	"gopkg.in/src-d/go-git.v4/config"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"testing"
+)
+
+func TestRepairCommand_Run(t *testing.T) {
+	ctx, stop := startTestGitHubServer(func(writer http.ResponseWriter, request *http.Request) {
+		assert.Equal(t, "/gists/cc33", request.URL.Path)
+		_, _ = writer.Write([]byte(`{"id":"cc33","created_at":"2020-01-01T00:00:00Z","owner":{"login":"test-user"}}`))
+	})
+	defer stop()
+	dir := prepareDeleteTestDir(t)
+	defer func() { _ = os.RemoveAll(dir) }()
+	ctx.CurrentProfiles[0].Dir = DestinationDir(dir)
+
+	repository, err := git.PlainInit(dir+"/my-snippet", false)
+	assert.Nil(t, err)
+	_, err = repository.CreateRemote(&config.RemoteConfig{
+		Name: git.DefaultRemoteName,
+		URLs: []string{"https://gist.github.com/cc33.git"},
+	})
+	assert.Nil(t, err)
+	// not a git repository
+	assert.Nil(t, os.Mkdir(dir+"/notes", 0755))
+
+	buffer := new(bytes.Buffer)
+	command := RepairCommand{ProfileName: "default", Writer: buffer}
+	err = command.Run(context.Background(), ctx)
+	assert.Nil(t, err, buffer.String())
+	assert.Contains(t, buffer.String(), "repaired cc33")
+	assert.Contains(t, buffer.String(), "repaired: 1, failed: 0")
+
+	items, err := LoadMetadataFrom(dir + "/.gist")
+	assert.Nil(t, err)
+	assert.Equal(t, 3, len(items))
+	assert.Equal(t, "cc33", items[2].ID)
+	assert.Equal(t, "my-snippet", items[2].Name)
+}
+
+func TestRepairCommand_Run_NothingToRepair(t *testing.T) {
+	ctx, stop := startTestGitHubServer(func(writer http.ResponseWriter, request *http.Request) {
+		assert.Fail(t, "unexpected request", request.URL.Path)
+	})
+	defer stop()
+	dir := prepareDeleteTestDir(t)
+	defer func() { _ = os.RemoveAll(dir) }()
+	ctx.CurrentProfiles[0].Dir = DestinationDir(dir)
+	before, err := ioutil.ReadFile(dir + "/.gist")
+	assert.Nil(t, err)
+
+	buffer := new(bytes.Buffer)
+	command := RepairCommand{ProfileName: "default", Writer: buffer}
+	err = command.Run(context.Background(), ctx)
+	assert.Nil(t, err)
+	assert.Equal(t, "repaired: 0, failed: 0\n", buffer.String())
+	after, err := ioutil.ReadFile(dir + "/.gist")
+	assert.Nil(t, err)
+	assert.Equal(t, before, after)
+}
@@ -1,20 +1,41 @@
 package main
 
 import (
-	"encoding/json"
 	"fmt"
-	"os"
+	"sort"
 	"time"
 )
 
 // RepositoryMetadata is metadata for each gist.
 type RepositoryMetadata struct {
-	ID      string `json:"id"`
-	Name    string `json:"name,omitempty"`
-	URL     string `json:"url"`
-	GitURL  string `json:"git_url"`
-	Owner   string `json:"owner"`
-	Created int64  `json:"created"`
+	ID          string `json:"id" xml:"id" yaml:"id"`
+	Name        string `json:"name,omitempty" xml:"name,omitempty" yaml:"name,omitempty"`
+	Description string `json:"description,omitempty" xml:"description,omitempty" yaml:"description,omitempty"`
+	URL         string `json:"url" xml:"url" yaml:"url"`
+	GitURL      string `json:"git_url" xml:"git_url" yaml:"git_url"`
+	Owner       string `json:"owner" xml:"owner" yaml:"owner"`
+	Created     int64  `json:"created" xml:"created" yaml:"created"`
+	Starred     bool   `json:"starred,omitempty" xml:"starred,omitempty" yaml:"starred,omitempty"`
+	ForkOf      string `json:"fork_of,omitempty" xml:"fork_of,omitempty" yaml:"fork_of,omitempty"`
+	HTMLURL     string `json:"html_url,omitempty" xml:"html_url,omitempty" yaml:"html_url,omitempty"`
+	// Public is nil for gists recorded before visibility is recorded, whose visibility is unknown.
+	Public   *bool          `json:"public,omitempty" xml:"public,omitempty" yaml:"public,omitempty"`
+	Updated  int64          `json:"updated,omitempty" xml:"updated,omitempty" yaml:"updated,omitempty"`
+	Comments int            `json:"comments,omitempty" xml:"comments,omitempty" yaml:"comments,omitempty"`
+	Forks    int            `json:"forks,omitempty" xml:"forks,omitempty" yaml:"forks,omitempty"`
+	Files    []FileMetadata `json:"files,omitempty" xml:"file,omitempty" yaml:"files,omitempty"`
+	// Path is the directory of the gist relative to destination directory. If empty, DirName is used.
+	Path string `json:"path,omitempty" xml:"path,omitempty" yaml:"path,omitempty"`
+}
+
+// FileMetadata is metadata for each file in gist.
+type FileMetadata struct {
+	Filename  string `json:"filename" xml:"filename" yaml:"filename"`
+	Language  string `json:"language,omitempty" xml:"language,omitempty" yaml:"language,omitempty"`
+	Type      string `json:"type,omitempty" xml:"type,omitempty" yaml:"type,omitempty"`
+	Size      int64  `json:"size" xml:"size" yaml:"size"`
+	RawURL    string `json:"raw_url,omitempty" xml:"raw_url,omitempty" yaml:"raw_url,omitempty"`
+	Truncated bool   `json:"truncated,omitempty" xml:"truncated,omitempty" yaml:"truncated,omitempty"`
 }
 
 // NewMetadataFromGist converts Gist into metadata.
@@ -23,35 +44,164 @@ func NewMetadataFromGist(repositoryName RepositoryName, gist Gist) (*RepositoryM
 	if err != nil {
 		return nil, err
 	}
-	return &RepositoryMetadata{
-		ID:      gist.ID,
-		Name:    string(repositoryName),
-		URL:     gist.URL,
-		GitURL:  gist.GitURL,
-		Owner:   gist.Owner.Login,
-		Created: createdAt.Unix(),
-	}, nil
+	public := gist.Public
+	metadata := RepositoryMetadata{
+		ID:          gist.ID,
+		Name:        string(repositoryName),
+		Description: gist.Description,
+		URL:         gist.URL,
+		GitURL:      gist.GitURL,
+		Owner:       gist.Owner.Login,
+		Created:     createdAt.Unix(),
+		HTMLURL:     gist.HTMLURL,
+		Public:      &public,
+		Comments:    gist.Comments,
+		Forks:       len(gist.Forks),
+	}
+	if gist.UpdatedAt != "" {
+		updatedAt, err := time.Parse("2006-01-02T15:04:05Z", gist.UpdatedAt)
+		if err != nil {
+			return nil, err
+		}
+		metadata.Updated = updatedAt.Unix()
+	}
+	if gist.ForkOf != nil {
+		metadata.ForkOf = gist.ForkOf.ID
+	}
+	names := make([]string, 0, len(gist.Files))
+	for name := range gist.Files {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		file := gist.Files[name]
+		metadata.Files = append(metadata.Files, FileMetadata{
+			Filename:  name,
+			Language:  file.Language,
+			Type:      file.Type,
+			Size:      file.Size,
+			RawURL:    file.RawURL,
+			Truncated: file.Truncated,
+		})
+	}
+	return &metadata, nil
+}
+
+// Languages returns languages of files in the gist without duplication.
+func (md *RepositoryMetadata) Languages() []string {
+	languages := make([]string, 0, len(md.Files))
+	found := make(map[string]bool, len(md.Files))
+	for _, file := range md.Files {
+		if file.Language == "" || found[file.Language] {
+			continue
+		}
+		found[file.Language] = true
+		languages = append(languages, file.Language)
+	}
+	return languages
+}
+
+// inheritFrom copies fields, which are recorded only in local, from previous metadata of the same gist.
+func (md *RepositoryMetadata) inheritFrom(previous RepositoryMetadata) {
+	md.Starred = previous.Starred
+	md.Path = previous.Path
+	if md.ForkOf == "" {
+		md.ForkOf = previous.ForkOf
+	}
+}
+
+// LoadMetadataFrom loads all RepositoryMetadata from metadata index file. If file is not existing, empty slice will be returned.
+func LoadMetadataFrom(path string) ([]RepositoryMetadata, error) {
+	index, err := LoadMetadataIndex(path)
+	if err != nil {
+		return nil, fmt.Errorf("LoadMetadataFrom: %w", err)
+	}
+	return index.Items(), nil
+}
+
+// FindMetadata returns index of RepositoryMetadata whose id or name is equal to idOrName.
+// idOrName can also be a reference to the gist accepted by ParseGistReference, e.g. url of the gist.
+// If no item matches, -1 will be returned.
+func FindMetadata(items []RepositoryMetadata, idOrName string) int {
+	for i, md := range items {
+		if md.ID == idOrName || (md.Name != "" && md.Name == idOrName) {
+			return i
+		}
+	}
+	gistID, err := ParseGistReference(idOrName)
+	if err != nil {
+		return -1
+	}
+	for i, md := range items {
+		if md.ID == string(*gistID) {
+			return i
+		}
+	}
+	return -1
 }
 
-// AppendTo appends RepositoryMetadata to file. If file is not existing, the file will be created.
-func (md *RepositoryMetadata) AppendTo(path string) error {
-	file, err := os.OpenFile(path, os.O_RDWR|os.O_CREATE|os.O_APPEND, 0644)
+// ResolveGistID returns id of the gist given by id or name, with index of its metadata.
+// If the gist is not cloned, idOrName should be a reference to the gist(id or url) and index will be -1.
+func ResolveGistID(items []RepositoryMetadata, idOrName string) (GistID, int, error) {
+	index := FindMetadata(items, idOrName)
+	if index >= 0 {
+		return GistID(items[index].ID), index, nil
+	}
+	gistID, err := ParseGistReference(idOrName)
 	if err != nil {
-		return fmt.Errorf("RepositoryMetadata_AppendTo_OpenFile: %w", err)
+		return "", -1, fmt.Errorf("no cloned gist found(name = %s): %w", idOrName, err)
 	}
-	defer func() { _ = file.Close() }()
+	return *gistID, -1, nil
+}
+
+// gistTarget is a gist given by id or name, with metadata index of the profile.
+type gistTarget struct {
+	GistID
+	DestinationDir
+	MetadataIndex *MetadataIndex
+	// Metadata is the entry of the gist in the index. If the gist is not cloned, it is nil.
+	Metadata *RepositoryMetadata
+}
 
-	bytes, err := json.Marshal(*md)
+// resolveGistTarget resolves a gist given by id or name recorded in metadata file of the profile.
+// A gist which is not cloned can be given only by its reference, e.g. id or url.
+func resolveGistTarget(pctx ProfileContext, profileName ProfileName, target string) (*gistTarget, error) {
+	destinationDir, err := pctx.Dir(profileName)
 	if err != nil {
-		return fmt.Errorf("Repositorymetadata_AppendTo_MarshalJson: %w", err)
+		return nil, fmt.Errorf("ResolveGistTarget_ProfileContext_Dir: %w", err)
 	}
-	_, err = file.Write(bytes)
+	metadataFile, err := destinationDir.Resolve(".gist")
 	if err != nil {
-		return fmt.Errorf("Repositorymetadata_AppendTo_Write: %w", err)
+		return nil, fmt.Errorf("ResolveGistTarget_Resolve: %w", err)
 	}
-	_, err = file.WriteString("\n")
+	metadataIndex, err := LoadMetadataIndex(metadataFile)
 	if err != nil {
-		return fmt.Errorf("Repositorymetadata_AppendTo_WriteNewLine: %w", err)
+		return nil, fmt.Errorf("ResolveGistTarget_LoadMetadataIndex: %w", err)
+	}
+	items := metadataIndex.Items()
+	gistID, index, err := ResolveGistID(items, target)
+	if err != nil {
+		return nil, fmt.Errorf("ResolveGistTarget_ResolveGistID: %w", err)
+	}
+	resolved := &gistTarget{GistID: gistID, DestinationDir: destinationDir, MetadataIndex: metadataIndex}
+	if index >= 0 {
+		resolved.Metadata = &items[index]
+	}
+	return resolved, nil
+}
+
+// DirName is directory name of the gist under destination directory.
+func (md *RepositoryMetadata) DirName() string {
+	if md.Name == "" {
+		return md.ID
+	}
+	return md.Name
+}
+
+// RelativePath is the directory of the gist relative to destination directory, which depends on layout at clone.
+func (md *RepositoryMetadata) RelativePath() string {
+	if md.Path == "" {
+		return md.DirName()
 	}
-	return nil
+	return md.Path
 }
@@ -3,93 +3,19 @@ package main
 import (
 	"bufio"
 	"encoding/json"
+	"fmt"
 	"github.com/stretchr/testify/assert"
-	"io/ioutil"
 	"os"
 	"testing"
 	"time"
 )
 
-func TestRepositoryMetadata_AppendTo_NewFile(t *testing.T) {
-	newFile := "build/test/new-metadata.jsonl"
-	_, err := os.Stat("build/test")
-	if err != nil {
-		_ = os.MkdirAll("build", 0644)
-	}
-	metadata := RepositoryMetadata{
-		ID:      "1a2bc3d4ef",
-		Name:    "test",
-		URL:     "https://api.github.com/gists/1a2bc3d4ef",
-		GitURL:  "git@github.com/gists/1a2bc3d4ef.git",
-		Owner:   "test-user",
-		Created: time.Now().Unix(),
-	}
-	err = metadata.AppendTo(newFile)
-	assert.Nil(t, err)
-	file, err := os.Open(newFile)
-	if err != nil {
-		assert.Fail(t, "unexpected error@open", err)
-		return
-	}
-	defer func() { _ = file.Close() }()
-	bytes, err := ioutil.ReadAll(file)
-	if err != nil {
-		assert.Fail(t, "unexpected error@read", err)
-		return
-	}
-	var repositoryMetadata RepositoryMetadata
-	err = json.Unmarshal(bytes, &repositoryMetadata)
-	if err != nil {
-		assert.Fail(t, "unexpected error@unmarshal", err)
-		return
-	}
-	assert.Equal(t, metadata, repositoryMetadata)
-}
-
-func TestRepositoryMetadata_AppendTo_Existing(t *testing.T) {
-	existingFile := "build/test/existing-metadata.jsonl"
-	existingData := RepositoryMetadata{
-		ID:      "1a2bc3d4ef",
-		Name:    "test",
-		URL:     "https://api.github.com/gists/1a2bc3d4ef",
-		GitURL:  "git@github.com/gists/1a2bc3d4ef.git",
-		Owner:   "test-user",
-		Created: time.Now().Unix(),
-	}
-	err := prepareExistingMetadataFile(existingFile, existingData)
-	if err != nil {
-		assert.Fail(t, "unexpected error@prepare file", err)
-		return
-	}
-
-	metadata := RepositoryMetadata{
-		ID:      "1100aaccb2",
-		Name:    "new-repository",
-		URL:     "https://api.github.com/gists/1100aaccb2",
-		GitURL:  "git@github.com/gists/1100aaccb2.git",
-		Owner:   "new-user",
-		Created: time.Now().Unix(),
-	}
-
-	err = metadata.AppendTo(existingFile)
-	assert.Nil(t, err)
-
-	items, err := readExistingMetadataFile(existingFile)
-	if err != nil {
-		assert.Fail(t, "unexpected error@read file", err)
-		return
-	}
-	assert.Equal(t, 2, len(items))
-	assert.Equal(t, existingData, items[0])
-	assert.Equal(t, metadata, items[1])
-}
-
 func prepareExistingMetadataFile(existingFile string, metadata RepositoryMetadata) error {
-	_, err := os.Stat("build/test")
+	err := os.MkdirAll("build/test", 0755)
 	if err != nil {
-		_ = os.MkdirAll("build", 0644)
+		return err
 	}
-	f, err := os.OpenFile(existingFile, os.O_RDWR|os.O_CREATE, 0644)
+	f, err := os.OpenFile(existingFile, os.O_RDWR|os.O_CREATE|os.O_TRUNC, 0644)
 	if err != nil {
 		return err
 	}
@@ -109,6 +35,7 @@ func prepareExistingMetadataFile(existingFile string, metadata RepositoryMetadat
 	return nil
 }
 
+// readExistingMetadataFile reads metadata file written in the current schema.
 func readExistingMetadataFile(existingFile string) ([]RepositoryMetadata, error) {
 	f, err := os.Open(existingFile)
 	if err != nil {
@@ -116,6 +43,9 @@ func readExistingMetadataFile(existingFile string) ([]RepositoryMetadata, error)
 	}
 	defer func() { _ = f.Close() }()
 	scanner := bufio.NewScanner(f)
+	if !scanner.Scan() || scanner.Text() != `{"version":2}` {
+		return nil, fmt.Errorf("no header: %s", scanner.Text())
+	}
 	items := make([]RepositoryMetadata, 0)
 	for scanner.Scan() {
 		line := scanner.Text()
@@ -129,3 +59,99 @@ func readExistingMetadataFile(existingFile string) ([]RepositoryMetadata, error)
 	}
 	return items, nil
 }
+
+func TestLoadMetadataFrom(t *testing.T) {
+	_ = os.MkdirAll("build/test", 0755)
+	existingFile := "build/test/load-metadata.jsonl"
+	existingData := RepositoryMetadata{
+		ID:      "1a2bc3d4ef",
+		Name:    "test",
+		URL:     "https://api.github.com/gists/1a2bc3d4ef",
+		GitURL:  "git@github.com/gists/1a2bc3d4ef.git",
+		Owner:   "test-user",
+		Created: time.Now().Unix(),
+	}
+	err := prepareExistingMetadataFile(existingFile, existingData)
+	if err != nil {
+		assert.Fail(t, "unexpected error@prepare file", err)
+		return
+	}
+	items, err := LoadMetadataFrom(existingFile)
+	assert.Nil(t, err)
+	assert.Equal(t, []RepositoryMetadata{existingData}, items)
+}
+
+func TestLoadMetadataFrom_NotExisting(t *testing.T) {
+	items, err := LoadMetadataFrom("build/test/not-existing-metadata.jsonl")
+	assert.Nil(t, err)
+	assert.Equal(t, 0, len(items))
+}
+
+func TestFindMetadata(t *testing.T) {
+	items := []RepositoryMetadata{
+		{ID: "aa11", Name: "first"},
+		{ID: "bb22"},
+	}
+	assert.Equal(t, 0, FindMetadata(items, "aa11"))
+	assert.Equal(t, 0, FindMetadata(items, "first"))
+	assert.Equal(t, 1, FindMetadata(items, "bb22"))
+	assert.Equal(t, -1, FindMetadata(items, ""))
+	assert.Equal(t, -1, FindMetadata(items, "cc33"))
+}
+
+func TestRepositoryMetadata_DirName(t *testing.T) {
+	named := RepositoryMetadata{ID: "aa11", Name: "first"}
+	assert.Equal(t, "first", named.DirName())
+	unnamed := RepositoryMetadata{ID: "bb22"}
+	assert.Equal(t, "bb22", unnamed.DirName())
+}
+
+func TestResolveGistID(t *testing.T) {
+	items := []RepositoryMetadata{
+		{ID: "aa11", Name: "first"},
+	}
+	gistID, index, err := ResolveGistID(items, "first")
+	assert.Nil(t, err)
+	assert.Equal(t, GistID("aa11"), gistID)
+	assert.Equal(t, 0, index)
+	gistID, index, err = ResolveGistID(items, "bb22")
+	assert.Nil(t, err)
+	assert.Equal(t, GistID("bb22"), gistID)
+	assert.Equal(t, -1, index)
+	_, _, err = ResolveGistID(items, "second")
+	assert.NotNil(t, err)
+}
+
+func TestNewMetadataFromGist_FilesAndStatus(t *testing.T) {
+	metadata, err := NewMetadataFromGist("test", Gist{
+		ID:        "aa11",
+		HTMLURL:   "https://gist.github.com/aa11",
+		Public:    true,
+		CreatedAt: "2020-01-01T00:00:00Z",
+		UpdatedAt: "2020-02-01T00:00:00Z",
+		Comments:  2,
+		Forks:     []GistFork{{ID: "bb22"}},
+		Files: map[string]GistFile{
+			"main.go":   {Filename: "main.go", Language: "Go", Type: "text/plain", Size: 100, RawURL: "https://example.com/main.go"},
+			"README.md": {Filename: "README.md", Language: "Markdown", Size: 20},
+			"util.go":   {Filename: "util.go", Language: "Go", Size: 30, Truncated: true},
+		},
+	})
+	assert.Nil(t, err)
+	assert.Equal(t, "https://gist.github.com/aa11", metadata.HTMLURL)
+	assert.True(t, *metadata.Public)
+	assert.Equal(t, int64(1580515200), metadata.Updated)
+	assert.Equal(t, 2, metadata.Comments)
+	assert.Equal(t, 1, metadata.Forks)
+	assert.Equal(t, []FileMetadata{
+		{Filename: "README.md", Language: "Markdown", Size: 20},
+		{Filename: "main.go", Language: "Go", Type: "text/plain", Size: 100, RawURL: "https://example.com/main.go"},
+		{Filename: "util.go", Language: "Go", Size: 30, Truncated: true},
+	}, metadata.Files)
+	assert.Equal(t, []string{"Markdown", "Go"}, metadata.Languages())
+}
+
+func TestNewMetadataFromGist_InvalidUpdatedAt(t *testing.T) {
+	_, err := NewMetadataFromGist("", Gist{ID: "aa11", CreatedAt: "2020-01-01T00:00:00Z", UpdatedAt: "yesterday"})
+	assert.NotNil(t, err)
+}
@@ -0,0 +1,56 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+)
+
+// StarCommand stars or unstars a gist, and records it in metadata of the cloned gist.
+type StarCommand struct {
+	ProfileName
+	// Target is id or name of a gist. Only id is available for a gist which is not cloned.
+	Target string
+	// Unstar removes star instead.
+	Unstar bool
+	Writer io.Writer
+}
+
+// Run command of StarCommand
+func (sc *StarCommand) Run(ctx context.Context, pctx ProfileContext) error {
+	if sc.Writer == nil {
+		return errors.New("StarCommand_Run: writer is not given")
+	}
+	target, err := resolveGistTarget(pctx, sc.ProfileName, sc.Target)
+	if err != nil {
+		return fmt.Errorf("StarCommand_Run_ResolveTarget: %w", err)
+	}
+	gistID := target.GistID
+
+	gitHub := pctx.NewGitHub()
+	if sc.Unstar {
+		err = gitHub.Unstar(ctx, gistID, sc.ProfileName)
+	} else {
+		err = gitHub.Star(ctx, gistID, sc.ProfileName)
+	}
+	if err != nil {
+		return fmt.Errorf("StarCommand_Run_Star: %w", err)
+	}
+	if sc.Unstar {
+		_, _ = fmt.Fprintf(sc.Writer, "unstarred %s\n", gistID)
+	} else {
+		_, _ = fmt.Fprintf(sc.Writer, "starred %s\n", gistID)
+	}
+	if target.Metadata == nil {
+		return nil
+	}
+	md := *target.Metadata
+	md.Starred = !sc.Unstar
+	target.MetadataIndex.Upsert(md)
+	err = target.MetadataIndex.Save()
+	if err != nil {
+		return fmt.Errorf("StarCommand_Run_SaveMetadata: %w", err)
+	}
+	return nil
+}
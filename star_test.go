@@ -0,0 +1,67 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"github.com/stretchr/testify/assert"
+	"net/http"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestStarCommand_Run_UpdatesMetadata(t *testing.T) {
+	ctx, stop := startTestGitHubServer(func(writer http.ResponseWriter, request *http.Request) {
+		assert.Equal(t, "PUT", request.Method)
+		assert.Equal(t, "/gists/aa11/star", request.URL.Path)
+		writer.WriteHeader(http.StatusNoContent)
+	})
+	defer stop()
+	parent := prepareDeleteTestDir(t)
+	defer func() { _ = os.RemoveAll(parent) }()
+	ctx.CurrentProfiles[0].Dir = DestinationDir(parent)
+
+	buffer := new(bytes.Buffer)
+	command := StarCommand{ProfileName: "default", Target: "first", Writer: buffer}
+	err := command.Run(context.Background(), ctx)
+	assert.Nil(t, err)
+	assert.Equal(t, "starred aa11\n", buffer.String())
+	items, err := LoadMetadataFrom(filepath.Join(parent, ".gist"))
+	assert.Nil(t, err)
+	assert.Equal(t, []RepositoryMetadata{{ID: "aa11", Name: "first", Starred: true}, {ID: "bb22"}}, items)
+}
+
+func TestStarCommand_Run_UnstarNotCloned(t *testing.T) {
+	ctx, stop := startTestGitHubServer(func(writer http.ResponseWriter, request *http.Request) {
+		assert.Equal(t, "DELETE", request.Method)
+		assert.Equal(t, "/gists/cc33/star", request.URL.Path)
+		writer.WriteHeader(http.StatusNoContent)
+	})
+	defer stop()
+	parent := prepareDeleteTestDir(t)
+	defer func() { _ = os.RemoveAll(parent) }()
+	ctx.CurrentProfiles[0].Dir = DestinationDir(parent)
+
+	buffer := new(bytes.Buffer)
+	command := StarCommand{ProfileName: "default", Target: "cc33", Unstar: true, Writer: buffer}
+	err := command.Run(context.Background(), ctx)
+	assert.Nil(t, err)
+	assert.Equal(t, "unstarred cc33\n", buffer.String())
+}
+
+func TestStarCommand_Run_Error(t *testing.T) {
+	ctx, stop := startTestGitHubServer(func(writer http.ResponseWriter, request *http.Request) {
+		writer.WriteHeader(http.StatusNotFound)
+	})
+	defer stop()
+	parent := prepareDeleteTestDir(t)
+	defer func() { _ = os.RemoveAll(parent) }()
+	ctx.CurrentProfiles[0].Dir = DestinationDir(parent)
+
+	command := StarCommand{ProfileName: "default", Target: "aa11", Writer: new(bytes.Buffer)}
+	err := command.Run(context.Background(), ctx)
+	assert.NotNil(t, err)
+	items, err := LoadMetadataFrom(filepath.Join(parent, ".gist"))
+	assert.Nil(t, err)
+	assert.False(t, items[0].Starred)
+}